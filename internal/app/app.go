@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -15,6 +17,9 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/example/validacion-pases/internal/config"
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/ics"
+	"github.com/example/validacion-pases/internal/platform/unlocode"
 	"github.com/example/validacion-pases/internal/repository/mysql"
 	"github.com/example/validacion-pases/internal/security/auth"
 	secheaders "github.com/example/validacion-pases/internal/security/headers"
@@ -23,6 +28,17 @@ import (
 	"github.com/example/validacion-pases/internal/usecase"
 )
 
+const (
+	// scopeScansRead grants access to the gate scan log.
+	scopeScansRead = "scans:read"
+	// scopeGateConsume allows gate devices to count uses of limited-use passes.
+	scopeGateConsume = "gate:consume"
+	// scopeTerminalsWrite allows editing the terminal catalogue.
+	scopeTerminalsWrite = "terminals:write"
+	// scopeTariffsOverride allows issuing passes with more free days than the client's tariff.
+	scopeTariffsOverride = "tariffs:override"
+)
+
 func New(ctx context.Context, cfg config.Config, db *sql.DB, logger *slog.Logger) (http.Handler, error) {
 	validator, err := auth.NewJWTValidator(ctx, cfg.JWTAlg, cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTClockSkew, cfg.JWTHSSecret, cfg.JWKSURL, cfg.JWTRefresh)
 	if err != nil {
@@ -32,7 +48,7 @@ func New(ctx context.Context, cfg config.Config, db *sql.DB, logger *slog.Logger
 	var tokenSvc *usecase.TokenService
 	issuer, issueErr := auth.NewTokenIssuer(cfg.JWTAlg, cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTTokenTTL, cfg.JWTHSSecret)
 	if issueErr == nil {
-		userStore := auth.NewUserStore(cfg.TokenUsers)
+		userStore := auth.NewUserStore(cfg.TokenUsers).WithScopes(cfg.TokenScopes)
 		tokenSvc = usecase.NewTokenService(userStore, issuer)
 	} else {
 		logger.Warn("token issuance disabled", "reason", issueErr.Error())
@@ -40,17 +56,48 @@ func New(ctx context.Context, cfg config.Config, db *sql.DB, logger *slog.Logger
 	}
 
 	repo := mysql.NewRecordRepository(db)
-	qrVerifier := usecase.NewCompactQRTokenVerifier(cfg.QRTokenSecret)
-	svc := usecase.NewRecordService(repo, qrVerifier)
+	qrVerifier, qrIssuer, err := newQRTokenComponents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := unlocode.Load(cfg.PortDefaultCountry)
+	if err != nil {
+		return nil, err
+	}
+	terminals := usecase.NewTerminalCatalog(mysql.NewTerminalRepository(db), cfg.TerminalCacheTTL)
+	var icsHolidays []domain.Holiday
+	for _, f := range cfg.HolidayICSFiles {
+		holidays, err := ics.LoadHolidays(f.Path, f.TerminalCode)
+		if err != nil {
+			return nil, fmt.Errorf("load holidays from %s: %w", f.Path, err)
+		}
+		icsHolidays = append(icsHolidays, holidays...)
+	}
+	svc := usecase.NewRecordService(repo, qrVerifier).
+		WithQRTokenIssuer(qrIssuer).
+		WithRevocations(mysql.NewRevocationRepository(db)).
+		WithScanLog(mysql.NewScanRepository(db)).
+		WithExtensions(mysql.NewExtensionRepository(db)).
+		WithTerminals(terminals, cfg.TerminalUnknownPort == "reject").
+		WithPorts(ports).
+		WithFreeTime(usecase.NewFreeTimeRules(mysql.NewFreeTimeRepository(db), cfg.ReferenceDataCacheTTL, icsHolidays...)).
+		WithTimezone(cfg.OperatingTimezone).
+		WithTariffs(usecase.NewClientTariffs(mysql.NewClientTariffRepository(db), cfg.ReferenceDataCacheTTL))
+	idempotency := middleware.Idempotency(mysql.NewIdempotencyRepository(db), cfg.IdempotencyTTL)
 	health := handlers.NewHealthHandler(db)
-	records := handlers.NewRecordHandler(svc)
+	records := handlers.NewRecordHandler(svc).
+		WithPublicBaseURL(cfg.PublicBaseURL).
+		WithTariffOverrideScope(scopeTariffsOverride).
+		WithExportWriteTimeout(cfg.WriteTimeout).
+		WithImportTimeout(cfg.ImportTimeout)
 	tokenHandler := handlers.NewTokenHandler(tokenSvc)
+	catalog := handlers.NewCatalogHandler().WithPorts(ports)
+	terminalHandler := handlers.NewTerminalHandler(terminals)
 
 	r := chi.NewRouter()
 	r.Use(chimiddleware.RequestID)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestLog(logger))
-	r.Use(chimiddleware.Timeout(cfg.RequestTimeout))
 	r.Use(chimiddleware.RealIP)
 	r.Use(chimiddleware.Heartbeat("/ping"))
 	r.Use(chimiddleware.Compress(5))
@@ -58,28 +105,60 @@ func New(ctx context.Context, cfg config.Config, db *sql.DB, logger *slog.Logger
 	r.Use(httprate.LimitByIP(cfg.RateLimitRequests, cfg.RateLimitWindow))
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
-		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID", "X-Scanner-ID", "If-None-Match", "If-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"X-Request-ID", "ETag", "Idempotent-Replayed"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 	r.Use(secheaders.Middleware)
-	r.Use(bodyLimit(cfg.BodyLimitBytes))
 
 	reg := prometheus.NewRegistry()
 	metrics := middleware.NewMetrics(reg)
 	r.Use(metrics.Middleware)
 
-	r.Get("/healthz", health.Liveness)
-	r.Get("/readyz", health.Readiness)
-	r.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	// Every route runs under RequestTimeout and HTTP_BODY_LIMIT_BYTES except the export, which
+	// streams for as long as the client keeps reading and extends its write deadline per chunk,
+	// and the import, which has its own timeout and body limit sized for large manifests.
+	timeout := chimiddleware.Timeout(cfg.RequestTimeout)
+	limit := bodyLimit(cfg.BodyLimitBytes)
+
+	r.With(timeout, limit).Get("/healthz", health.Liveness)
+	r.With(timeout, limit).Get("/readyz", health.Readiness)
+	r.With(timeout, limit).Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 
 	r.Route("/v1", func(v1 chi.Router) {
-		v1.Use(chimiddleware.AllowContentType("application/json"))
-		v1.Post("/token", tokenHandler.Issue)
-		v1.With(middleware.AuthBearer(validator)).Post("/records", records.Create)
-		v1.Get("/records/validate", records.Validate)
+		v1.With(limit, middleware.AuthBearer(validator), chimiddleware.AllowContentType("application/json")).Get("/records/export", records.Export)
+		v1.With(chimiddleware.Timeout(cfg.ImportTimeout), bodyLimit(cfg.ImportBodyLimitBytes), middleware.AuthBearer(validator), chimiddleware.AllowContentType("multipart/form-data")).Post("/records:import", records.Import)
+		v1.Group(func(api chi.Router) {
+			api.Use(timeout)
+			api.Use(limit)
+			api.Use(chimiddleware.AllowContentType("application/json"))
+			api.Post("/token", tokenHandler.Issue)
+			api.With(middleware.AuthBearer(validator), idempotency).Post("/records", records.Create)
+			api.With(middleware.AuthBearer(validator)).Get("/records", records.List)
+			api.With(middleware.OptionalAuthBearer(validator)).Get("/records/validate", records.Validate)
+			api.With(middleware.AuthBearer(validator), middleware.RequireScope(scopeGateConsume)).Post("/records/consume", records.Consume)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}", records.Get)
+			api.With(middleware.AuthBearer(validator)).Patch("/records/{id}", records.Amend)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}/versions", records.ListVersions)
+			api.With(middleware.AuthBearer(validator)).Post("/records/{id}/qr-token", records.IssueQRToken)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}/qr.png", records.QRCodePNG)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}/qr.svg", records.QRCodeSVG)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}/pass.pdf", records.PassPDF)
+			api.With(middleware.AuthBearer(validator)).Post("/records/{id}/revoke", records.Revoke)
+			api.With(middleware.AuthBearer(validator)).Post("/records/{id}/extensions", records.Extend)
+			api.With(middleware.AuthBearer(validator)).Get("/records/{id}/extensions", records.ListExtensions)
+			api.With(middleware.AuthBearer(validator), middleware.RequireScope(scopeScansRead)).Get("/records/{id}/scans", records.ListScans)
+			api.With(middleware.AuthBearer(validator)).Get("/catalog/container-types", catalog.ContainerTypes)
+			api.With(middleware.AuthBearer(validator)).Get("/catalog/ports", catalog.Ports)
+			api.With(middleware.AuthBearer(validator)).Get("/catalog/ports/{code}", catalog.Port)
+			api.With(middleware.AuthBearer(validator)).Get("/terminals", terminalHandler.List)
+			api.With(middleware.AuthBearer(validator)).Get("/terminals/{id}", terminalHandler.Get)
+			api.With(middleware.AuthBearer(validator), middleware.RequireScope(scopeTerminalsWrite)).Post("/terminals", terminalHandler.Create)
+			api.With(middleware.AuthBearer(validator), middleware.RequireScope(scopeTerminalsWrite)).Put("/terminals/{id}", terminalHandler.Update)
+			api.With(middleware.AuthBearer(validator), middleware.RequireScope(scopeTerminalsWrite)).Delete("/terminals/{id}", terminalHandler.Delete)
+		})
 	})
 
 	wrapped := otelhttp.NewHandler(r, "http.server", otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
@@ -89,6 +168,75 @@ func New(ctx context.Context, cfg config.Config, db *sql.DB, logger *slog.Logger
 	return wrapped, nil
 }
 
+// newQRTokenComponents builds a verifier accepting every configured token version and key id,
+// and an issuer that signs with the active keyring key or, without a keyring, the version
+// selected by QR_TOKEN_VERSION.
+func newQRTokenComponents(cfg config.Config) (usecase.QRTokenVerifier, usecase.QRTokenIssuer, error) {
+	verifiers := map[string]usecase.QRTokenVerifier{
+		"v1": usecase.NewCompactQRTokenVerifier(cfg.QRTokenSecret),
+	}
+
+	var privateKey ed25519.PrivateKey
+	if cfg.QRTokenEd25519Key != "" {
+		key, err := usecase.ParseEd25519PrivateKey(cfg.QRTokenEd25519Key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("QR_TOKEN_ED25519_PRIVATE_KEY: %w", err)
+		}
+		privateKey = key
+	}
+	switch {
+	case cfg.QRTokenEd25519PubKey != "":
+		publicKey, err := usecase.ParseEd25519PublicKey(cfg.QRTokenEd25519PubKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("QR_TOKEN_ED25519_PUBLIC_KEY: %w", err)
+		}
+		verifiers["v2"] = usecase.NewEd25519QRTokenVerifier(publicKey)
+	case privateKey != nil:
+		verifiers["v2"] = usecase.NewEd25519QRTokenVerifier(privateKey.Public().(ed25519.PublicKey))
+	}
+
+	var issuer usecase.QRTokenIssuer = usecase.NewCompactQRTokenIssuer(cfg.QRTokenSecret, cfg.QRTokenTTL, cfg.QRTokenMaxTTL)
+	if cfg.QRTokenVersion == "v2" {
+		issuer = usecase.NewEd25519QRTokenIssuer(privateKey, cfg.QRTokenTTL, cfg.QRTokenMaxTTL)
+	}
+	var verifier usecase.QRTokenVerifier = usecase.NewVersionedQRTokenVerifier(verifiers)
+
+	if len(cfg.QRKeys) == 0 {
+		return verifier, issuer, nil
+	}
+	keyring, err := newQRKeyring(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.QRActiveKeyID != "" {
+		issuer = usecase.NewKeyringQRTokenIssuer(keyring, cfg.QRTokenTTL, cfg.QRTokenMaxTTL)
+	}
+	return usecase.NewKeyringQRTokenVerifier(keyring, verifier), issuer, nil
+}
+
+func newQRKeyring(cfg config.Config) (*usecase.QRKeyring, error) {
+	keys := make([]usecase.QRSigningKey, 0, len(cfg.QRKeys))
+	for _, k := range cfg.QRKeys {
+		key := usecase.QRSigningKey{ID: k.ID, Version: k.Version, Secret: []byte(k.Secret), VerifyUntil: k.VerifyUntil}
+		if k.PrivateKey != "" {
+			privateKey, err := usecase.ParseEd25519PrivateKey(k.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("qr key %s: %w", k.ID, err)
+			}
+			key.PrivateKey = privateKey
+		}
+		if k.PublicKey != "" {
+			publicKey, err := usecase.ParseEd25519PublicKey(k.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("qr key %s: %w", k.ID, err)
+			}
+			key.PublicKey = publicKey
+		}
+		keys = append(keys, key)
+	}
+	return usecase.NewQRKeyring(cfg.QRActiveKeyID, keys)
+}
+
 func bodyLimit(limit int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -16,6 +16,7 @@ type Config struct {
 	ServiceName       string
 	Environment       string
 	HTTPAddr          string
+	PublicBaseURL     string
 	ReadTimeout       time.Duration
 	ReadHeaderTimeout time.Duration
 	WriteTimeout      time.Duration
@@ -41,11 +42,43 @@ type Config struct {
 	JWTHSSecret   string
 	JWTTokenTTL   time.Duration
 	TokenUsers    map[string]string
+	TokenScopes   map[string][]string
 	QRTokenSecret string
+	QRTokenTTL    time.Duration
+	QRTokenMaxTTL time.Duration
+	// QRTokenVersion selects the format used when issuing QR tokens (v1 HMAC or v2 Ed25519).
+	QRTokenVersion       string
+	QRTokenEd25519Key    string
+	QRTokenEd25519PubKey string
+	QRKeys               []QRKey
+	QRActiveKeyID        string
 
 	RateLimitRequests int
 	RateLimitWindow   time.Duration
 	AllowedOrigins    []string
+	// IdempotencyTTL is how long a response stored under an Idempotency-Key is replayed.
+	IdempotencyTTL time.Duration
+	// TerminalCacheTTL bounds how long the in-process terminal catalogue may miss changes made
+	// through other instances.
+	TerminalCacheTTL time.Duration
+	// TerminalUnknownPort is "flag" (accept with a warning) or "reject" for ports missing from
+	// the terminal catalogue.
+	TerminalUnknownPort string
+	// PortDefaultCountry settles port names shared by several countries (e.g. Manzanillo) when
+	// puerto_descargue does not name the country.
+	PortDefaultCountry string
+	// HolidayICSFiles add holidays from iCalendar files to those in terminal_holidays.
+	HolidayICSFiles []HolidayICSFile
+	// OperatingTimezone gives business dates (today, date filters, printed times) for terminals
+	// without a timezone of their own.
+	OperatingTimezone *time.Location
+	// ReferenceDataCacheTTL bounds how long holidays, client free-time policies and client
+	// tariffs cached in process may miss database changes.
+	ReferenceDataCacheTTL time.Duration
+	// ImportBodyLimitBytes and ImportTimeout replace HTTP_BODY_LIMIT_BYTES and the request, read
+	// and write timeouts for POST /v1/records:import, sized for manifests of maxImportRows rows.
+	ImportBodyLimitBytes int64
+	ImportTimeout        time.Duration
 
 	OTelEnabled  bool
 	OTelEndpoint string
@@ -58,6 +91,7 @@ func Load() (Config, error) {
 		ServiceName:       getEnv("SERVICE_NAME", "validacion-pases"),
 		Environment:       getEnv("ENV", "dev"),
 		HTTPAddr:          getEnv("HTTP_ADDR", ":8080"),
+		PublicBaseURL:     getEnv("PUBLIC_BASE_URL", ""),
 		ReadTimeout:       mustDuration("HTTP_READ_TIMEOUT", "10s"),
 		ReadHeaderTimeout: mustDuration("HTTP_READ_HEADER_TIMEOUT", "5s"),
 		WriteTimeout:      mustDuration("HTTP_WRITE_TIMEOUT", "15s"),
@@ -83,17 +117,46 @@ func Load() (Config, error) {
 		JWTHSSecret:   getEnv("JWT_HS_SECRET", ""),
 		JWTTokenTTL:   mustDuration("JWT_TOKEN_TTL", "1h"),
 		TokenUsers:    parseTokenUsers(getEnv("TOKEN_USERS", "apiuser:change-me")),
+		TokenScopes:   parseTokenScopes(getEnv("TOKEN_USER_SCOPES", "")),
 		QRTokenSecret: getEnv("QR_TOKEN_SECRET", getEnv("PASE_QR_SECRET", "")),
+		QRTokenTTL:    mustDuration("QR_TOKEN_TTL", "720h"),
+		QRTokenMaxTTL: mustDuration("QR_TOKEN_MAX_TTL", "2160h"),
+
+		QRTokenVersion:       strings.ToLower(getEnv("QR_TOKEN_VERSION", "v1")),
+		QRTokenEd25519Key:    getEnv("QR_TOKEN_ED25519_PRIVATE_KEY", ""),
+		QRTokenEd25519PubKey: getEnv("QR_TOKEN_ED25519_PUBLIC_KEY", ""),
+		QRActiveKeyID:        getEnv("QR_ACTIVE_KEY_ID", ""),
 
 		RateLimitRequests: mustInt("RATE_LIMIT_REQUESTS", 100),
 		RateLimitWindow:   mustDuration("RATE_LIMIT_WINDOW", "1m"),
 		AllowedOrigins:    splitCSV(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")),
+		IdempotencyTTL:    mustDuration("IDEMPOTENCY_TTL", "24h"),
+
+		TerminalCacheTTL:    mustDuration("TERMINAL_CACHE_TTL", "1m"),
+		TerminalUnknownPort: strings.ToLower(getEnv("TERMINAL_UNKNOWN_PORT", "flag")),
+		PortDefaultCountry:  strings.ToUpper(getEnv("PORT_DEFAULT_COUNTRY", "PA")),
+		HolidayICSFiles:     parseHolidayICSFiles(getEnv("HOLIDAY_ICS_FILES", "")),
+
+		ReferenceDataCacheTTL: mustDuration("REFERENCE_DATA_CACHE_TTL", "1m"),
+		ImportBodyLimitBytes:  mustInt64("IMPORT_BODY_LIMIT_BYTES", 10485760),
+		ImportTimeout:         mustDuration("IMPORT_TIMEOUT", "2m"),
 
 		OTelEnabled:  mustBool("OTEL_ENABLED", false),
 		OTelEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel-collector:4318"),
 		OTelInsecure: mustBool("OTEL_EXPORTER_OTLP_INSECURE", true),
 	}
 
+	qrKeys, err := loadQRKeys(getEnv("QR_KEYRING", ""), getEnv("QR_KEYRING_DIR", ""))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.QRKeys = qrKeys
+
+	tz := getEnv("OPERATING_TIMEZONE", "America/Panama")
+	if cfg.OperatingTimezone, err = time.LoadLocation(tz); err != nil || tz == "Local" {
+		return Config{}, errors.New("OPERATING_TIMEZONE must be an IANA zone name such as America/Panama")
+	}
+
 	if cfg.AuthMode != "jwt" {
 		return Config{}, errors.New("only AUTH_MODE=jwt is implemented")
 	}
@@ -103,6 +166,36 @@ func Load() (Config, error) {
 	if cfg.JWTAlg == "HS256" && cfg.JWTHSSecret == "" {
 		return Config{}, errors.New("JWT_HS_SECRET is required when JWT_ALG=HS256")
 	}
+	if cfg.QRTokenTTL <= 0 || cfg.QRTokenTTL > cfg.QRTokenMaxTTL {
+		return Config{}, errors.New("QR_TOKEN_TTL must be positive and not exceed QR_TOKEN_MAX_TTL")
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		return Config{}, errors.New("IDEMPOTENCY_TTL must be positive")
+	}
+	if cfg.TerminalCacheTTL < 0 {
+		return Config{}, errors.New("TERMINAL_CACHE_TTL must not be negative")
+	}
+	if cfg.ReferenceDataCacheTTL < 0 {
+		return Config{}, errors.New("REFERENCE_DATA_CACHE_TTL must not be negative")
+	}
+	if cfg.ImportBodyLimitBytes <= 0 || cfg.ImportTimeout <= 0 {
+		return Config{}, errors.New("IMPORT_BODY_LIMIT_BYTES and IMPORT_TIMEOUT must be positive")
+	}
+	if cfg.TerminalUnknownPort != "flag" && cfg.TerminalUnknownPort != "reject" {
+		return Config{}, errors.New("TERMINAL_UNKNOWN_PORT must be flag or reject")
+	}
+	if cfg.PortDefaultCountry != "" && len(cfg.PortDefaultCountry) != 2 {
+		return Config{}, errors.New("PORT_DEFAULT_COUNTRY must be an ISO 3166 alpha-2 code or empty")
+	}
+	if cfg.QRTokenVersion != "v1" && cfg.QRTokenVersion != "v2" {
+		return Config{}, errors.New("QR_TOKEN_VERSION must be v1 or v2")
+	}
+	if cfg.QRTokenVersion == "v2" && cfg.QRTokenEd25519Key == "" {
+		return Config{}, errors.New("QR_TOKEN_ED25519_PRIVATE_KEY is required when QR_TOKEN_VERSION=v2")
+	}
+	if cfg.QRActiveKeyID != "" && len(cfg.QRKeys) == 0 {
+		return Config{}, errors.New("QR_ACTIVE_KEY_ID requires QR_KEYRING or QR_KEYRING_DIR")
+	}
 	if len(cfg.TokenUsers) == 0 {
 		return Config{}, errors.New("TOKEN_USERS must include at least one user:password pair")
 	}
@@ -110,6 +203,29 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
+// HolidayICSFile is a holiday calendar for the terminal with TerminalCode, or for every terminal
+// when TerminalCode is empty.
+type HolidayICSFile struct {
+	TerminalCode string
+	Path         string
+}
+
+// parseHolidayICSFiles reads `PABLB=/etc/pases/pablb.ics,/etc/pases/panama.ics`; entries without a
+// terminal code apply to every terminal.
+func parseHolidayICSFiles(raw string) []HolidayICSFile {
+	var files []HolidayICSFile
+	for _, entry := range splitCSV(raw) {
+		code, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			code, path = "", entry
+		}
+		if path = strings.TrimSpace(path); path != "" {
+			files = append(files, HolidayICSFile{TerminalCode: strings.ToUpper(strings.TrimSpace(code)), Path: path})
+		}
+	}
+	return files
+}
+
 func parseTokenUsers(raw string) map[string]string {
 	users := make(map[string]string)
 	entries := splitCSV(raw)
@@ -128,6 +244,27 @@ func parseTokenUsers(raw string) map[string]string {
 	return users
 }
 
+// parseTokenScopes reads `user:scope1|scope2,user2:scope3` into extra scopes per user.
+func parseTokenScopes(raw string) map[string][]string {
+	scopes := make(map[string][]string)
+	for _, entry := range splitCSV(raw) {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		user := strings.TrimSpace(parts[0])
+		if user == "" {
+			continue
+		}
+		for _, scope := range strings.Split(parts[1], "|") {
+			if trimmed := strings.TrimSpace(scope); trimmed != "" {
+				scopes[user] = append(scopes[user], trimmed)
+			}
+		}
+	}
+	return scopes
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
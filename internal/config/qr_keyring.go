@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QRKey declares one QR signing key, either inline in QR_KEYRING (JSON array)
+// or as a single JSON object per file in QR_KEYRING_DIR.
+type QRKey struct {
+	ID         string `json:"kid"`
+	Version    string `json:"version"`
+	Secret     string `json:"secret,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+	// VerifyUntil is the RFC 3339 cutoff after which tokens from this key are rejected once it is retired.
+	VerifyUntil time.Time `json:"verify_until,omitempty"`
+}
+
+func loadQRKeys(inline, dir string) ([]QRKey, error) {
+	var keys []QRKey
+	if strings.TrimSpace(inline) != "" {
+		if err := json.Unmarshal([]byte(inline), &keys); err != nil {
+			return nil, fmt.Errorf("invalid QR_KEYRING: %w", err)
+		}
+	}
+
+	if strings.TrimSpace(dir) == "" {
+		return keys, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QR_KEYRING_DIR: %w", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read qr key %s: %w", file, err)
+		}
+		var key QRKey
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return nil, fmt.Errorf("invalid qr key %s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClientTariff is the free time contracted by a client. Empty Rama and ContainerType (an ISO
+// size/type code, known only for nacional lines) match any pass. ValidFrom and ValidTo are
+// inclusive calendar dates compared with fecha_real; a zero ValidTo leaves the tariff open-ended.
+type ClientTariff struct {
+	ID               int64
+	Cliente          string
+	Rama             string
+	ContainerType    string
+	DefaultDiasLibre int
+	MaxDiasLibre     int
+	ValidFrom        time.Time
+	ValidTo          time.Time
+}
+
+// Applies reports whether the tariff covers a pass of rama with containerTypes discharged on
+// fechaReal. A tariff for a container type covers the pass only when every line has that type.
+func (t ClientTariff) Applies(rama string, containerTypes []string, fechaReal time.Time) bool {
+	if t.Rama != "" && !strings.EqualFold(t.Rama, rama) {
+		return false
+	}
+	if t.ContainerType != "" {
+		if len(containerTypes) == 0 {
+			return false
+		}
+		for _, ct := range containerTypes {
+			if !strings.EqualFold(ct, t.ContainerType) {
+				return false
+			}
+		}
+	}
+	return !fechaReal.Before(t.ValidFrom) && (t.ValidTo.IsZero() || !fechaReal.After(t.ValidTo))
+}
+
+// ClientTariffRepository reads the client tariffs, maintained in the database.
+type ClientTariffRepository interface {
+	List(ctx context.Context) ([]ClientTariff, error)
+}
+
+// TariffLimitError reports dias_libre above the maximum contracted by the client. It matches
+// ErrInvalidInput with errors.Is.
+type TariffLimitError struct {
+	Cliente   string
+	DiasLibre int
+	Max       int
+}
+
+func (e *TariffLimitError) Error() string {
+	return fmt.Sprintf("dias_libre %d exceeds the %d free days contracted by %s", e.DiasLibre, e.Max, e.Cliente)
+}
+
+func (e *TariffLimitError) Unwrap() error { return ErrInvalidInput }
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContainerPart names the component of an ISO 6346 container number that failed validation.
+type ContainerPart string
+
+const (
+	ContainerPartLength     ContainerPart = "length"
+	ContainerPartOwnerCode  ContainerPart = "owner_code"
+	ContainerPartCategory   ContainerPart = "category"
+	ContainerPartSerial     ContainerPart = "serial"
+	ContainerPartCheckDigit ContainerPart = "check_digit"
+)
+
+// ContainerNumberError describes why a container number is not valid ISO 6346. ExpectedCheckDigit
+// is set only when Part is ContainerPartCheckDigit. It matches ErrInvalidInput with errors.Is.
+type ContainerNumberError struct {
+	Value              string
+	Part               ContainerPart
+	Reason             string
+	ExpectedCheckDigit int
+}
+
+func (e *ContainerNumberError) Error() string {
+	return fmt.Sprintf("container number %q: %s", e.Value, e.Reason)
+}
+
+func (e *ContainerNumberError) Unwrap() error { return ErrInvalidInput }
+
+// ContainerNumber is an ISO 6346 container identification: a three-letter owner code, a category
+// identifier (U freight container, J detachable equipment, Z trailer or chassis), a six-digit
+// serial number and a check digit.
+type ContainerNumber struct {
+	OwnerCode  string
+	Category   byte
+	Serial     string
+	CheckDigit int
+}
+
+// String returns the number in its compact form, e.g. MSCU1234566.
+func (c ContainerNumber) String() string {
+	return fmt.Sprintf("%s%c%s%d", c.OwnerCode, c.Category, c.Serial, c.CheckDigit)
+}
+
+// ParseContainerNumber normalizes raw (uppercase, without spaces, dashes, dots or slashes) and
+// validates it as an ISO 6346 container number, reporting the first part that is wrong.
+func ParseContainerNumber(raw string) (ContainerNumber, error) {
+	value := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.', '/':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(raw))
+
+	fail := func(part ContainerPart, reason string) (ContainerNumber, error) {
+		return ContainerNumber{}, &ContainerNumberError{Value: value, Part: part, Reason: reason, ExpectedCheckDigit: -1}
+	}
+	if len(value) != 11 {
+		return fail(ContainerPartLength, fmt.Sprintf("must have 11 characters (4 letters and 7 digits), got %d", len(value)))
+	}
+	for i := 0; i < 3; i++ {
+		if value[i] < 'A' || value[i] > 'Z' {
+			return fail(ContainerPartOwnerCode, "owner code must be three letters")
+		}
+	}
+	if value[3] != 'U' && value[3] != 'J' && value[3] != 'Z' {
+		return fail(ContainerPartCategory, "category identifier must be U, J or Z")
+	}
+	for i := 4; i < 10; i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return fail(ContainerPartSerial, "serial number must be six digits")
+		}
+	}
+	if value[10] < '0' || value[10] > '9' {
+		return fail(ContainerPartCheckDigit, "check digit must be a digit")
+	}
+
+	expected := containerCheckDigit(value[:10])
+	if got := int(value[10] - '0'); got != expected {
+		return ContainerNumber{}, &ContainerNumberError{
+			Value:              value,
+			Part:               ContainerPartCheckDigit,
+			Reason:             fmt.Sprintf("check digit %d is wrong, expected %d", got, expected),
+			ExpectedCheckDigit: expected,
+		}
+	}
+	return ContainerNumber{OwnerCode: value[:3], Category: value[3], Serial: value[4:10], CheckDigit: expected}, nil
+}
+
+// containerCheckDigit computes the ISO 6346 check digit of the first ten characters. Letters
+// take the values 10 to 38 skipping multiples of 11; each position is weighted by 2^i and the
+// sum modulo 11 gives the digit, with 10 written as 0.
+func containerCheckDigit(prefix string) int {
+	sum := 0
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		var v int
+		if c >= 'A' && c <= 'Z' {
+			v = 10 + int(c-'A')
+			v += (v - 1) / 10 // skip 11, 22 and 33
+		} else {
+			v = int(c - '0')
+		}
+		sum += v << i
+	}
+	return sum % 11 % 10
+}
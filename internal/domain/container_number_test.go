@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseContainerNumberNormalizes(t *testing.T) {
+	for _, raw := range []string{"CSQU3054383", "csqu 305438-3", "CSQU-305438.3"} {
+		cn, err := ParseContainerNumber(raw)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", raw, err)
+		}
+		if cn.String() != "CSQU3054383" || cn.OwnerCode != "CSQ" || cn.Category != 'U' || cn.Serial != "305438" {
+			t.Fatalf("%q: unexpected container number %+v", raw, cn)
+		}
+	}
+}
+
+func TestParseContainerNumberReportsPart(t *testing.T) {
+	cases := []struct {
+		raw      string
+		part     ContainerPart
+		expected int
+	}{
+		{"MSCU123", ContainerPartLength, -1},
+		{"M5CU1234566", ContainerPartOwnerCode, -1},
+		{"MSCX1234566", ContainerPartCategory, -1},
+		{"MSCU12O4566", ContainerPartSerial, -1},
+		{"MSCU123456X", ContainerPartCheckDigit, -1},
+		{"MSCU1234567", ContainerPartCheckDigit, 6},
+		{"CSQU3045383", ContainerPartCheckDigit, 1},
+	}
+	for _, tc := range cases {
+		_, err := ParseContainerNumber(tc.raw)
+		var cnErr *ContainerNumberError
+		if !errors.As(err, &cnErr) {
+			t.Fatalf("%q: expected ContainerNumberError, got %v", tc.raw, err)
+		}
+		if cnErr.Part != tc.part || cnErr.ExpectedCheckDigit != tc.expected {
+			t.Fatalf("%q: expected part %s and check digit %d, got %+v", tc.raw, tc.part, tc.expected, cnErr)
+		}
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%q: expected ErrInvalidInput", tc.raw)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnknownContainerType = errors.New("unknown ISO size/type code")
+
+// ContainerType is an ISO 6346 size/type code with its human description, as printed on passes.
+type ContainerType struct {
+	Code        string
+	Length      string
+	Height      string
+	Group       string
+	Description string
+}
+
+// containerTypes is the built-in size/type catalogue. The first character of a code is the
+// length (2 = 20', 4 = 40', L = 45'), the second the height (0 = 8', 2 = 8'6", 5 = 9'6") and the
+// last two the type group and detail.
+var containerTypes = []ContainerType{
+	{Code: "20G1", Length: "20'", Height: "8'", Group: "GP", Description: "20' DRY LOW"},
+	{Code: "22G0", Length: "20'", Height: "8'6\"", Group: "GP", Description: "20' DRY"},
+	{Code: "22G1", Length: "20'", Height: "8'6\"", Group: "GP", Description: "20' DRY"},
+	{Code: "25G1", Length: "20'", Height: "9'6\"", Group: "GP", Description: "20' HIGH CUBE"},
+	{Code: "22V1", Length: "20'", Height: "8'6\"", Group: "VH", Description: "20' VENTILATED"},
+	{Code: "22R1", Length: "20'", Height: "8'6\"", Group: "RE", Description: "20' REEFER"},
+	{Code: "22U1", Length: "20'", Height: "8'6\"", Group: "UT", Description: "20' OPEN TOP"},
+	{Code: "22P1", Length: "20'", Height: "8'6\"", Group: "PL", Description: "20' FLAT RACK"},
+	{Code: "22P3", Length: "20'", Height: "8'6\"", Group: "PC", Description: "20' COLLAPSIBLE FLAT RACK"},
+	{Code: "22T6", Length: "20'", Height: "8'6\"", Group: "TN", Description: "20' TANK"},
+	{Code: "42G0", Length: "40'", Height: "8'6\"", Group: "GP", Description: "40' DRY"},
+	{Code: "42G1", Length: "40'", Height: "8'6\"", Group: "GP", Description: "40' DRY"},
+	{Code: "45G0", Length: "40'", Height: "9'6\"", Group: "GP", Description: "40' HIGH CUBE"},
+	{Code: "45G1", Length: "40'", Height: "9'6\"", Group: "GP", Description: "40' HIGH CUBE"},
+	{Code: "42R1", Length: "40'", Height: "8'6\"", Group: "RE", Description: "40' REEFER"},
+	{Code: "45R1", Length: "40'", Height: "9'6\"", Group: "RE", Description: "40' HIGH CUBE REEFER"},
+	{Code: "42U1", Length: "40'", Height: "8'6\"", Group: "UT", Description: "40' OPEN TOP"},
+	{Code: "45U1", Length: "40'", Height: "9'6\"", Group: "UT", Description: "40' HIGH CUBE OPEN TOP"},
+	{Code: "42P1", Length: "40'", Height: "8'6\"", Group: "PL", Description: "40' FLAT RACK"},
+	{Code: "42P3", Length: "40'", Height: "8'6\"", Group: "PC", Description: "40' COLLAPSIBLE FLAT RACK"},
+	{Code: "45P3", Length: "40'", Height: "9'6\"", Group: "PC", Description: "40' HIGH CUBE COLLAPSIBLE FLAT RACK"},
+	{Code: "42T6", Length: "40'", Height: "8'6\"", Group: "TN", Description: "40' TANK"},
+	{Code: "L5G1", Length: "45'", Height: "9'6\"", Group: "GP", Description: "45' HIGH CUBE"},
+	{Code: "L5R1", Length: "45'", Height: "9'6\"", Group: "RE", Description: "45' HIGH CUBE REEFER"},
+}
+
+// ContainerTypes returns the size/type catalogue grouped by length.
+func ContainerTypes() []ContainerType {
+	return append([]ContainerType(nil), containerTypes...)
+}
+
+// LookupContainerType finds code in the catalogue, ignoring case and surrounding spaces.
+func LookupContainerType(code string) (ContainerType, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, ct := range containerTypes {
+		if ct.Code == code {
+			return ct, true
+		}
+	}
+	return ContainerType{}, false
+}
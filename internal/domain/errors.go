@@ -14,4 +14,6 @@ var (
 	ErrConflict = errors.New("conflict")
 	// ErrNotFound indicates the requested resource was not found.
 	ErrNotFound = errors.New("not found")
+	// ErrPreconditionFailed indicates the resource changed since the version the client read.
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Extension records extra free days granted on a pass after it was issued.
+type Extension struct {
+	ID            int64
+	RecordID      int64
+	Days          int
+	Reason        string
+	ApprovedBy    string
+	RequestedBy   string
+	PreviousUntil time.Time
+	NewUntil      time.Time
+	CreatedAt     time.Time
+}
+
+// ExtensionRepository defines persistence operations for free-time extensions.
+type ExtensionRepository interface {
+	// Extend applies the record amendment and stores the extension atomically, returning the
+	// extension id. A stale expectedVersion yields ErrPreconditionFailed.
+	Extend(ctx context.Context, record Record, expectedVersion int, change RecordVersion, extension Extension) (int64, error)
+	// ListByRecordID returns the extensions of a record, oldest first.
+	ListByRecordID(ctx context.Context, recordID int64) ([]Extension, error)
+}
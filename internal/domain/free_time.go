@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// FreeTimePolicy names how free days are counted from fecha_real. The policy chosen when a pass
+// is issued is stored on it and reused by amendments and extensions.
+type FreeTimePolicy string
+
+const (
+	// FreeTimeCalendarDays counts every day.
+	FreeTimeCalendarDays FreeTimePolicy = "calendar_days"
+	// FreeTimeBusinessDays skips Saturdays, Sundays and the holidays of the terminal.
+	FreeTimeBusinessDays FreeTimePolicy = "business_days"
+)
+
+// ParseFreeTimePolicy accepts a policy name, ignoring case and surrounding spaces.
+func ParseFreeTimePolicy(raw string) (FreeTimePolicy, bool) {
+	switch p := FreeTimePolicy(strings.ToLower(strings.TrimSpace(raw))); p {
+	case FreeTimeCalendarDays, FreeTimeBusinessDays:
+		return p, true
+	default:
+		return "", false
+	}
+}
+
+// Holiday is a non-working day of a terminal. An empty TerminalCode applies to every terminal
+// (national holidays). Yearly holidays repeat on the same month and day from Date onwards.
+type Holiday struct {
+	TerminalCode string
+	Date         time.Time
+	Name         string
+	Yearly       bool
+}
+
+// ClientFreeTimePolicy overrides the terminal policy for every pass of a client.
+type ClientFreeTimePolicy struct {
+	Cliente string
+	Policy  FreeTimePolicy
+}
+
+// FreeTimeRepository reads the holiday calendars and client policies, maintained in the database.
+type FreeTimeRepository interface {
+	ListHolidays(ctx context.Context) ([]Holiday, error)
+	ListClientPolicies(ctx context.Context) ([]ClientFreeTimePolicy, error)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotentRequest is a request identified by a client-supplied Idempotency-Key. StatusCode
+// is 0 while the first request is still being processed.
+type IdempotentRequest struct {
+	Subject     string
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// IdempotencyRepository stores idempotent requests and their responses.
+type IdempotencyRepository interface {
+	// Reserve claims the key for a new request. It returns ErrConflict when an unexpired
+	// entry already exists for the same subject and key.
+	Reserve(ctx context.Context, req IdempotentRequest) error
+	// Find returns the unexpired entry for subject and key, or ErrNotFound.
+	Find(ctx context.Context, subject, key string, now time.Time) (IdempotentRequest, error)
+	// Complete stores the response of a reserved request.
+	Complete(ctx context.Context, subject, key string, statusCode int, contentType string, body []byte) error
+	// Release drops a reservation so the request can be retried.
+	Release(ctx context.Context, subject, key string) error
+}
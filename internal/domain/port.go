@@ -0,0 +1,18 @@
+package domain
+
+// Port is a UN/LOCODE location: a 2-letter country code followed by a 3-character location
+// code, e.g. PABLB for Balboa, Panama.
+type Port struct {
+	Code        string
+	Name        string
+	Country     string
+	CountryName string
+}
+
+// DisplayName is the name stored on records and printed on passes, e.g. "Balboa, Panamá".
+func (p Port) DisplayName() string {
+	if p.CountryName == "" {
+		return p.Name
+	}
+	return p.Name + ", " + p.CountryName
+}
@@ -7,21 +7,56 @@ import (
 
 // Record represents a persisted pass validation record.
 type Record struct {
-	ID                  int64
-	Emision             time.Time
-	Nave                string
-	Viaje               string
-	Cliente             string
-	Booking             string
-	Rama                string
-	Contenedor          string
-	PuertoDescargue     string
+	ID              int64
+	Emision         time.Time
+	Nave            string
+	Viaje           string
+	Cliente         string
+	Booking         string
+	Rama            string
+	Contenedor      string
+	PuertoDescargue string
+	// PuertoLocode and PuertoNombre are the UN/LOCODE and display name PuertoDescargue resolved
+	// to; both are empty when it did not match a known port.
+	PuertoLocode string
+	PuertoNombre string
+	// FechaReal is the discharge date entered by the operator; free time is counted from it. It
+	// is zero for legacy payloads that only sent LibreRetencionHasta; see BaseFechaReal.
+	FechaReal           time.Time
 	LibreRetencionHasta time.Time
 	DiasLibre           int
 	Transportista       string
 	TituloTerminal      string
 	UsuarioFirma        string
-	CreatedAt           time.Time
+	Status              RecordStatus
+	StatusUpdatedAt     time.Time
+	// MaxUses limits how many times the pass can be consumed at the gate; 0 means unlimited.
+	MaxUses  int
+	UseCount int
+	// Version starts at 1 and increases with every amendment.
+	Version   int
+	CreatedAt time.Time
+	// Containers are the container lines of the pass; Contenedor is their display string, e.g.
+	// "MSCU1234566 / YMLU5374938" or "2 X 22G1". Only FindByID and Search load them.
+	Containers []ContainerLine
+	// UnknownTerminal is set by Create when PuertoDescargue is not in the terminal catalogue and
+	// the record was accepted with a fallback title. It is not stored.
+	UnknownTerminal bool
+	// UnknownPort is set by Create when PuertoDescargue did not resolve to a UN/LOCODE. It is
+	// not stored.
+	UnknownPort bool
+	// FreeTimePolicy is how DiasLibre was counted to reach LibreRetencionHasta.
+	FreeTimePolicy FreeTimePolicy
+}
+
+// BaseFechaReal returns the date free time is counted from: FechaReal when the operator entered
+// it, otherwise LibreRetencionHasta minus DiasLibre. Legacy payloads always count calendar days,
+// so the derived date is exact for them.
+func (r Record) BaseFechaReal() time.Time {
+	if !r.FechaReal.IsZero() {
+		return r.FechaReal
+	}
+	return r.LibreRetencionHasta.AddDate(0, 0, -r.DiasLibre)
 }
 
 // CreateRecordInput contains the required fields to create a new record.
@@ -34,14 +69,80 @@ type CreateRecordInput struct {
 	ContenedorSerie string
 	CodigoISO       string
 	FechaReal       time.Time
-	DiasLibre       *int
-	Transportista   string
+	// LibreRetencionHasta is the legacy alternative to FechaReal: when FechaReal is zero the
+	// discharge date is taken as LibreRetencionHasta minus DiasLibre.
+	LibreRetencionHasta time.Time
+	DiasLibre           *int
+	Transportista       string
+	PuertoDescargue     string
+	UsuarioFirma        string
+	MaxUses             *int
+	// Containers lists the containers of the pass. When empty, ContenedorSerie or CodigoISO
+	// describe a single container (the original one-container payload).
+	Containers []ContainerLine
+	// OverrideTariff allows DiasLibre above the maximum of the client's tariff.
+	OverrideTariff bool
+}
+
+// ContainerLine is one container line of a pass: an ISO 6346 serial for internacional records,
+// or an ISO size/type code with a quantity for nacional records.
+type ContainerLine struct {
+	Serie     string
+	CodigoISO string
+	Cantidad  int
+}
+
+// RecordFilter narrows a record search. Empty fields are ignored; text fields match exactly.
+// Time ranges are inclusive of From and exclusive of To. Results are ordered by id descending
+// and AfterID resumes a previous page (keyset pagination).
+type RecordFilter struct {
+	Booking         string
+	Viaje           string
+	Nave            string
+	Cliente         string
+	Contenedor      string
+	Rama            string
 	PuertoDescargue string
+	PuertoLocode    string
+	TituloTerminal  string
 	UsuarioFirma    string
+	EmisionFrom     time.Time
+	EmisionTo       time.Time
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	AfterID         int64
+	Limit           int
 }
 
 // RecordRepository defines persistence operations for records.
 type RecordRepository interface {
 	Insert(ctx context.Context, record Record) (int64, error)
 	FindByID(ctx context.Context, id int64) (Record, error)
+	// UpdateStatus moves a record from one status to another and returns ErrConflict
+	// when the stored status no longer matches from.
+	UpdateStatus(ctx context.Context, id int64, from, to RecordStatus, at time.Time) error
+	// ConsumeUse atomically counts one use of an issued pass, marking it used when MaxUses is
+	// reached, and returns ErrConflict when the pass is not issued or has no uses left.
+	ConsumeUse(ctx context.Context, id int64, at time.Time) error
+	// Search returns up to filter.Limit records matching filter, newest id first.
+	Search(ctx context.Context, filter RecordFilter) ([]Record, error)
+	// Stream calls fn for every record matching filter, oldest id first, reading rows from the
+	// database one at a time; filter.Limit and filter.AfterID are ignored. It stops at the first
+	// error returned by fn.
+	Stream(ctx context.Context, filter RecordFilter, fn func(Record) error) error
+	// Amend stores the editable fields of record and appends change to the version history
+	// atomically. It returns ErrPreconditionFailed when the stored version is not
+	// expectedVersion and ErrConflict when the amendment collides with another record.
+	Amend(ctx context.Context, record Record, expectedVersion int, change RecordVersion) error
+	// ListVersions returns the amendment history of a record, oldest first.
+	ListVersions(ctx context.Context, recordID int64) ([]RecordVersion, error)
+	// InsertBatch inserts records in a single transaction. Duplicates are reported per record
+	// as ErrConflict without aborting the batch; any other failure rolls the batch back.
+	InsertBatch(ctx context.Context, records []Record) ([]BatchInsertResult, error)
+}
+
+// BatchInsertResult is the outcome of one record of InsertBatch, in input order.
+type BatchInsertResult struct {
+	ID  int64
+	Err error
 }
@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// RecordStatus is the lifecycle state of a pass.
+type RecordStatus string
+
+const (
+	StatusIssued     RecordStatus = "issued"
+	StatusUsed       RecordStatus = "used"
+	StatusExpired    RecordStatus = "expired"
+	StatusRevoked    RecordStatus = "revoked"
+	StatusSuperseded RecordStatus = "superseded"
+)
+
+var recordStatusTransitions = map[RecordStatus][]RecordStatus{
+	StatusIssued:  {StatusUsed, StatusExpired, StatusRevoked, StatusSuperseded},
+	StatusUsed:    {StatusRevoked, StatusSuperseded},
+	StatusExpired: {StatusRevoked, StatusSuperseded},
+}
+
+// Valid reports whether s is one of the known lifecycle states.
+func (s RecordStatus) Valid() bool {
+	switch s {
+	case StatusIssued, StatusUsed, StatusExpired, StatusRevoked, StatusSuperseded:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanTransitionTo reports whether the state machine allows moving from s to next.
+// Revoked and superseded are terminal.
+func (s RecordStatus) CanTransitionTo(next RecordStatus) bool {
+	for _, allowed := range recordStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveStatus combines the stored status with the free-time deadline: an issued pass
+// whose LibreRetencionHasta day has passed is reported as expired. Today is the calendar date
+// of now in its own location, so callers pass now in the operating timezone of the pass.
+func (r Record) EffectiveStatus(now time.Time) RecordStatus {
+	status := r.Status
+	if status == "" {
+		status = StatusIssued
+	}
+	if status != StatusIssued || r.LibreRetencionHasta.IsZero() {
+		return status
+	}
+	y, m, d := r.LibreRetencionHasta.Date()
+	lastValidDay := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	ny, nm, nd := now.Date()
+	if time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC).After(lastValidDay) {
+		return StatusExpired
+	}
+	return status
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// FieldChange is the value of one record field before and after an amendment.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// RecordVersion is an append-only history entry describing the amendment that produced
+// Version of a record.
+type RecordVersion struct {
+	RecordID  int64
+	Version   int
+	ChangedBy string
+	ChangedAt time.Time
+	Changes   []FieldChange
+}
+
+// AmendRecordInput contains the fields to change on an existing record. Nil fields are kept.
+type AmendRecordInput struct {
+	Nave            *string
+	Viaje           *string
+	Cliente         *string
+	Booking         *string
+	Rama            *string
+	ContenedorSerie *string
+	CodigoISO       *string
+	Transportista   *string
+	PuertoDescargue *string
+	FechaReal       *time.Time
+	DiasLibre       *int
+	ChangedBy       string
+	// Containers replaces every container line of the pass. ContenedorSerie and CodigoISO may
+	// only amend a pass with a single line.
+	Containers *[]ContainerLine
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Revocation records that a pass was cancelled and must no longer be accepted at the gate.
+type Revocation struct {
+	RecordID  int64
+	Reason    string
+	RevokedBy string
+	RevokedAt time.Time
+}
+
+// RevocationRepository defines persistence operations for pass revocations.
+type RevocationRepository interface {
+	// Revoke stores the revocation and moves the record from status from to revoked
+	// atomically. A record no longer in from yields ErrConflict.
+	Revoke(ctx context.Context, revocation Revocation, from RecordStatus) error
+	FindByRecordID(ctx context.Context, recordID int64) (Revocation, error)
+}
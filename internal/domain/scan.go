@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ScanOutcome is the result of one validation attempt at the gate.
+type ScanOutcome string
+
+const (
+	ScanValid            ScanOutcome = "valid"
+	ScanExpired          ScanOutcome = "expired"
+	ScanInvalidSignature ScanOutcome = "invalid_signature"
+	ScanNotFound         ScanOutcome = "not_found"
+	ScanRevoked          ScanOutcome = "revoked"
+	ScanUsed             ScanOutcome = "used"
+	ScanSuperseded       ScanOutcome = "superseded"
+)
+
+// Scan is an append-only log entry for a validation attempt. RecordID is zero when the
+// token could not be resolved to a record.
+type Scan struct {
+	ID        int64
+	RecordID  int64
+	Outcome   ScanOutcome
+	Scanner   string
+	ClientIP  string
+	ScannedAt time.Time
+}
+
+// ScanRepository defines persistence operations for the gate scan log.
+type ScanRepository interface {
+	Insert(ctx context.Context, scan Scan) error
+	ListByRecordID(ctx context.Context, recordID int64, limit int) ([]Scan, error)
+}
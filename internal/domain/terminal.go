@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var ErrUnknownTerminal = errors.New("puerto_descargue is not in the terminal catalogue")
+
+// Terminal maps a port code and its aliases to the terminal printed on passes.
+type Terminal struct {
+	ID      int64
+	Code    string
+	Title   string
+	Address string
+	LogoURL string
+	// Aliases are other spellings of the port found in puerto_descargue, e.g. "PUERTO BALBOA".
+	Aliases   []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// FreeTimePolicy counts the free days of passes for this terminal unless the client has its
+	// own policy; empty means calendar days.
+	FreeTimePolicy FreeTimePolicy
+	// Timezone is the IANA name of the zone the terminal operates in; empty means the service's
+	// operating timezone (DefaultTimezone unless configured).
+	Timezone string
+}
+
+// DefaultTimezone is the operating timezone of terminals that do not set their own.
+const DefaultTimezone = "America/Panama"
+
+// TerminalRepository defines persistence operations for the terminal catalogue. Codes and aliases
+// are unique across terminals; duplicates are reported as ErrConflict.
+type TerminalRepository interface {
+	List(ctx context.Context) ([]Terminal, error)
+	FindByID(ctx context.Context, id int64) (Terminal, error)
+	Insert(ctx context.Context, terminal Terminal) (int64, error)
+	Update(ctx context.Context, terminal Terminal) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// DefaultTerminals is the catalogue seeded by the terminals migration. It is used when the
+// service runs without a terminal repository.
+func DefaultTerminals() []Terminal {
+	return []Terminal{
+		{Code: "PABLB", Title: "TERMINAL PACIFICO - BALBOA", Aliases: []string{"BALBOA"}},
+		{Code: "PACTB", Title: "TERMINAL ATLANTICO - CRISTOBAL", Aliases: []string{"CRISTOBAL"}},
+		{Code: "PAMIT", Title: "MANZANILLO INTERNATIONAL TERMINAL", Aliases: []string{"MANZANILLO"}},
+	}
+}
+
+// NormalizeTerminalKey uppercases a port code, alias or puerto_descargue and reduces anything
+// that is not a letter or digit to single spaces, so "Puerto de Balboa." becomes
+// "PUERTO DE BALBOA".
+func NormalizeTerminalKey(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToUpper(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// MatchTerminal finds the terminal for puerto. A code or alias equal to puerto wins; otherwise
+// the longest code or alias appearing in puerto as whole words is used.
+func MatchTerminal(terminals []Terminal, puerto string) (Terminal, bool) {
+	key := NormalizeTerminalKey(puerto)
+	if key == "" {
+		return Terminal{}, false
+	}
+	padded := " " + key + " "
+	best, bestLen := -1, 0
+	for i, t := range terminals {
+		for _, candidate := range append([]string{t.Code}, t.Aliases...) {
+			candidate = NormalizeTerminalKey(candidate)
+			switch {
+			case candidate == "":
+			case candidate == key:
+				return t, true
+			case len(candidate) > bestLen && strings.Contains(padded, " "+candidate+" "):
+				best, bestLen = i, len(candidate)
+			}
+		}
+	}
+	if best < 0 {
+		return Terminal{}, false
+	}
+	return terminals[best], true
+}
+
+// FallbackTerminalTitle is the title used for ports missing from the catalogue.
+func FallbackTerminalTitle(puerto string) string {
+	return "TERMINAL " + strings.TrimSpace(puerto)
+}
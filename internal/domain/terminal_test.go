@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestMatchTerminal(t *testing.T) {
+	terminals := []Terminal{
+		{Code: "PABLB", Title: "TERMINAL PACIFICO - BALBOA", Aliases: []string{"BALBOA"}},
+		{Code: "PAROD", Title: "PANAMA PORTS COMPANY (RODMAN)", Aliases: []string{"RODMAN", "PSA RODMAN"}},
+		{Code: "PAPSA", Title: "PSA PANAMA INTERNATIONAL TERMINAL", Aliases: []string{"PSA"}},
+	}
+	cases := []struct {
+		puerto string
+		code   string
+	}{
+		{"pablb", "PABLB"},
+		{"Puerto de Balboa.", "PABLB"},
+		{"PSA Rodman", "PAROD"},
+		{"psa", "PAPSA"},
+		{"BALBOAS", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		got, ok := MatchTerminal(terminals, c.puerto)
+		if ok != (c.code != "") || got.Code != c.code {
+			t.Fatalf("%q: expected %q, got %q (%v)", c.puerto, c.code, got.Code, ok)
+		}
+	}
+}
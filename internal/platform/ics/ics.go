@@ -0,0 +1,115 @@
+// Package ics reads holiday calendars published as iCalendar (RFC 5545) files.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// ParseHolidays reads the all-day events of an iCalendar file as holidays of terminalCode (empty
+// for every terminal). An event yields one holiday per day from DTSTART up to DTEND, which is
+// exclusive, or only DTSTART when DTEND is missing. Events with RRULE:FREQ=YEARLY become yearly
+// holidays and any other recurrence is rejected.
+func ParseHolidays(r io.Reader, terminalCode string) ([]domain.Holiday, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []domain.Holiday
+	var current *domain.Holiday
+	var end time.Time
+	for i, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		prop, params, _ := strings.Cut(strings.ToUpper(name), ";")
+		switch {
+		case prop == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &domain.Holiday{TerminalCode: terminalCode}
+			end = time.Time{}
+		case current == nil:
+		case prop == "DTSTART":
+			if current.Date, err = parseDate(prop, value, params); err != nil {
+				return nil, fmt.Errorf("ics: line %d: %w", i+1, err)
+			}
+		case prop == "DTEND":
+			if end, err = parseDate(prop, value, params); err != nil {
+				return nil, fmt.Errorf("ics: line %d: %w", i+1, err)
+			}
+		case prop == "SUMMARY":
+			current.Name = unescape(value)
+		case prop == "RRULE":
+			if !strings.EqualFold(value, "FREQ=YEARLY") {
+				return nil, fmt.Errorf("ics: line %d: unsupported recurrence %q", i+1, value)
+			}
+			current.Yearly = true
+		case prop == "END" && strings.EqualFold(value, "VEVENT"):
+			if current.Date.IsZero() {
+				return nil, fmt.Errorf("ics: line %d: event without DTSTART", i+1)
+			}
+			holidays = append(holidays, *current)
+			for d := current.Date.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+				next := *current
+				next.Date = d
+				holidays = append(holidays, next)
+			}
+			current = nil
+		}
+	}
+	return holidays, nil
+}
+
+// LoadHolidays reads the calendar at path; see ParseHolidays.
+func LoadHolidays(path, terminalCode string) (holidays []domain.Holiday, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	return ParseHolidays(f, terminalCode)
+}
+
+// unfold joins the continuation lines (starting with a space or tab) of an iCalendar file.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// parseDate reads DTSTART or DTEND as a date (VALUE=DATE:20260109) or the date part of a
+// date-time.
+func parseDate(prop, value, params string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(params, "VALUE=DATE") {
+		value, _, _ = strings.Cut(value, "T")
+	}
+	d, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q", prop, value)
+	}
+	return d, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(strings.TrimSpace(value))
+}
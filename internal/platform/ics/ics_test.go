@@ -0,0 +1,71 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const panamaCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20260109\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"SUMMARY:Día de los\r\n" +
+	"  Mártires\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20260216T000000\r\n" +
+	"SUMMARY:Carnaval\\, lunes\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays(strings.NewReader(panamaCalendar), "PABLB")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(holidays) != 2 {
+		t.Fatalf("expected 2 holidays, got %+v", holidays)
+	}
+	first := holidays[0]
+	if first.TerminalCode != "PABLB" || !first.Yearly || first.Name != "Día de los Mártires" ||
+		!first.Date.Equal(time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected holiday: %+v", first)
+	}
+	if second := holidays[1]; second.Yearly || second.Name != "Carnaval, lunes" || second.Date.Day() != 16 {
+		t.Fatalf("unexpected holiday: %+v", second)
+	}
+}
+
+func TestParseHolidaysRejectsUnsupportedRecurrence(t *testing.T) {
+	cal := "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20260101\nRRULE:FREQ=WEEKLY;BYDAY=MO\nEND:VEVENT\n"
+	if _, err := ParseHolidays(strings.NewReader(cal), ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseHolidaysExpandsMultiDayEvents(t *testing.T) {
+	cal := "BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20260216\n" +
+		"DTEND;VALUE=DATE:20260218\n" +
+		"SUMMARY:Carnaval\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20261103\n" +
+		"DTEND;VALUE=DATE:20261104\n" +
+		"SUMMARY:Separación de Colombia\n" +
+		"END:VEVENT\n"
+	holidays, err := ParseHolidays(strings.NewReader(cal), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, h := range holidays {
+		got = append(got, h.Date.Format("2006-01-02")+" "+h.Name)
+	}
+	want := []string{"2026-02-16 Carnaval", "2026-02-17 Carnaval", "2026-11-03 Separación de Colombia"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+// Package pdf renders printable pass documents using a pure-Go PDF writer.
+package pdf
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Pass holds the already formatted values printed on a pass document.
+type Pass struct {
+	RecordID            int64
+	TituloTerminal      string
+	TerminalAddress     string
+	Emision             string
+	Nave                string
+	Viaje               string
+	Cliente             string
+	Booking             string
+	Contenedor          string
+	PuertoDescargue     string
+	LibreRetencionHasta string
+	DiasLibre           int
+	DiasHabiles         bool // DiasLibre counts working days only
+	Transportista       string
+	UsuarioFirma        string
+	QRCodePNG           []byte
+	ValidationURL       string
+	GeneratedAt         time.Time
+}
+
+const (
+	pageMargin  = 15.0
+	labelWidth  = 60.0
+	rowHeight   = 8.0
+	qrImageSize = 55.0
+)
+
+// RenderPass writes an A4 pass with the business fields, the QR code and a signature block.
+func RenderPass(w io.Writer, p Pass) error {
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(pageMargin, pageMargin, pageMargin)
+	doc.SetAutoPageBreak(false, pageMargin)
+	if !p.GeneratedAt.IsZero() {
+		doc.SetCreationDate(p.GeneratedAt)
+	}
+	tr := doc.UnicodeTranslatorFromDescriptor("")
+	doc.SetTitle(tr(p.TituloTerminal), false)
+	doc.SetCreator("validacion-pases", false)
+	doc.AddPage()
+
+	doc.SetFont("Helvetica", "B", 16)
+	doc.CellFormat(0, 10, tr(p.TituloTerminal), "", 1, "C", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	if p.TerminalAddress != "" {
+		doc.CellFormat(0, 6, tr(p.TerminalAddress), "", 1, "C", false, 0, "")
+	}
+	doc.CellFormat(0, 6, tr("PASE DE SALIDA No. ")+strconv.FormatInt(p.RecordID, 10), "", 1, "C", false, 0, "")
+	doc.Ln(6)
+
+	rows := [][2]string{
+		{"EMISION", p.Emision},
+		{"NAVE", p.Nave},
+		{"VIAJE", p.Viaje},
+		{"CLIENTE", p.Cliente},
+		{"BOOKING", p.Booking},
+		{"CONTENEDOR", p.Contenedor},
+		{"PUERTO DESCARGUE", p.PuertoDescargue},
+		{"LIBRE DE RETENCION HASTA", p.LibreRetencionHasta},
+		{"DIAS LIBRE", diasLibre(p)},
+	}
+	if p.Transportista != "" {
+		rows = append(rows, [2]string{"TRANSPORTISTA", p.Transportista})
+	}
+	for _, row := range rows {
+		doc.SetFont("Helvetica", "B", 10)
+		doc.CellFormat(labelWidth, rowHeight, tr(row[0]), "1", 0, "L", false, 0, "")
+		doc.SetFont("Helvetica", "", 10)
+		doc.CellFormat(0, rowHeight, tr(row[1]), "1", 1, "L", false, 0, "")
+	}
+
+	if len(p.QRCodePNG) > 0 {
+		doc.Ln(8)
+		pageWidth, _ := doc.GetPageSize()
+		x := (pageWidth - qrImageSize) / 2
+		opts := fpdf.ImageOptions{ImageType: "PNG"}
+		doc.RegisterImageOptionsReader("qr", opts, bytes.NewReader(p.QRCodePNG))
+		doc.ImageOptions("qr", x, doc.GetY(), qrImageSize, qrImageSize, true, opts, 0, "")
+		doc.SetFont("Helvetica", "", 7)
+		doc.MultiCell(0, 4, tr(p.ValidationURL), "", "C", false)
+	}
+
+	doc.Ln(14)
+	doc.SetFont("Helvetica", "", 10)
+	signX := doc.GetX() + 40
+	signWidth := 100.0
+	doc.Line(signX, doc.GetY(), signX+signWidth, doc.GetY())
+	doc.SetX(signX)
+	doc.CellFormat(signWidth, 6, tr(p.UsuarioFirma), "", 1, "C", false, 0, "")
+	doc.SetX(signX)
+	doc.SetFont("Helvetica", "B", 9)
+	doc.CellFormat(signWidth, 5, tr("FIRMA AUTORIZADA"), "", 1, "C", false, 0, "")
+
+	if err := doc.Error(); err != nil {
+		return err
+	}
+	return doc.Output(w)
+}
+
+func diasLibre(p Pass) string {
+	if p.DiasHabiles {
+		return strconv.Itoa(p.DiasLibre) + " HABILES"
+	}
+	return strconv.Itoa(p.DiasLibre)
+}
@@ -0,0 +1,89 @@
+// Package qrcode renders QR codes as PNG or SVG images.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+var ErrInvalidLevel = errors.New("invalid qr error correction level")
+
+// Level is the QR error-correction level (L, M, Q or H).
+type Level string
+
+const (
+	LevelLow      Level = "L"
+	LevelMedium   Level = "M"
+	LevelQuartile Level = "Q"
+	LevelHigh     Level = "H"
+)
+
+// ParseLevel accepts L, M, Q or H (case-insensitive); an empty value yields LevelMedium.
+func ParseLevel(raw string) (Level, error) {
+	switch Level(strings.ToUpper(strings.TrimSpace(raw))) {
+	case "", LevelMedium:
+		return LevelMedium, nil
+	case LevelLow:
+		return LevelLow, nil
+	case LevelQuartile:
+		return LevelQuartile, nil
+	case LevelHigh:
+		return LevelHigh, nil
+	default:
+		return "", ErrInvalidLevel
+	}
+}
+
+func (l Level) recoveryLevel() goqrcode.RecoveryLevel {
+	switch l {
+	case LevelLow:
+		return goqrcode.Low
+	case LevelQuartile:
+		return goqrcode.High
+	case LevelHigh:
+		return goqrcode.Highest
+	default:
+		return goqrcode.Medium
+	}
+}
+
+// PNG encodes content as a square PNG image of size x size pixels.
+func PNG(content string, size int, level Level) ([]byte, error) {
+	q, err := goqrcode.New(content, level.recoveryLevel())
+	if err != nil {
+		return nil, err
+	}
+	return q.PNG(size)
+}
+
+// SVG encodes content as a square SVG document of size x size pixels.
+// Dark modules are merged into horizontal runs to keep the document small.
+func SVG(content string, size int, level Level) ([]byte, error) {
+	q, err := goqrcode.New(content, level.recoveryLevel())
+	if err != nil {
+		return nil, err
+	}
+	bitmap := q.Bitmap()
+	modules := len(bitmap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return []byte(b.String()), nil
+}
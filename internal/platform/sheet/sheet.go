@@ -0,0 +1,211 @@
+// Package sheet reads and writes tabular files (CSV and XLSX) exchanged with shipping lines
+// and back-office users.
+package sheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported sheet format")
+
+// Format identifies a tabular file format.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat accepts csv or xlsx (case-insensitive).
+func ParseFormat(raw string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(raw))) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// FormatFromFilename infers the format from the file extension.
+func FormatFromFilename(name string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// ReadAll returns every row of the file, with row i holding line i+1; for XLSX only the first
+// worksheet is read. CSV files may use comma or semicolon separators, and a UTF-8 byte order
+// mark is ignored.
+func ReadAll(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header, _, _ := strings.Cut(text, "\n"); strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		// Keep blank lines as empty rows so that row indexes match file lines.
+		line, _ := reader.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func readXLSX(r io.Reader) (rows [][]string, err error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("read xlsx: workbook has no sheets")
+	}
+	rows, err = f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx: %w", err)
+	}
+	return rows, nil
+}
+
+// Writer appends rows to a tabular file. CSV cells starting with a formula character are written
+// with a leading apostrophe. Close completes the file; Abort releases its resources without
+// completing it.
+type Writer interface {
+	WriteRow(cells []string) error
+	Close() error
+	Abort()
+}
+
+// NewWriter returns a Writer producing format on w. CSV rows reach w as they are written; XLSX
+// rows are buffered by the workbook stream writer (spilling to a temporary file when large) and
+// the document is written to w on Close.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		f := excelize.NewFile()
+		sw, err := f.NewStreamWriter(f.GetSheetName(0))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &xlsxWriter{out: w, file: f, stream: sw}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ContentType returns the media type of format.
+func ContentType(format Format) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	values := make([]string, len(cells))
+	for i, v := range cells {
+		values[i] = neutralizeFormula(v)
+	}
+	return c.w.Write(values)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Abort() {}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+func (x *xlsxWriter) WriteRow(cells []string) error {
+	x.rows++
+	cell, err := excelize.CoordinatesToCellName(1, x.rows)
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(cells))
+	for i, v := range cells {
+		values[i] = v
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+// neutralizeFormula prefixes an apostrophe to CSV values that a spreadsheet would evaluate as a
+// formula, so operator-entered text such as "=HYPERLINK(...)" stays text when the file is opened.
+// XLSX cells are typed as strings and never evaluated, so they are written unchanged.
+func neutralizeFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func (x *xlsxWriter) Close() (err error) {
+	defer func() {
+		if cerr := x.file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	_, err = x.file.WriteTo(x.out)
+	return err
+}
+
+func (x *xlsxWriter) Abort() {
+	_ = x.file.Close()
+}
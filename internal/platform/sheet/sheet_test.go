@@ -0,0 +1,111 @@
+package sheet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadAllCSVDetectsSemicolonAndBOM(t *testing.T) {
+	rows, err := ReadAll(strings.NewReader("\ufeffNAVE;VIAJE\nNYK DENEB;072E\n"), FormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0][0] != "NAVE" || rows[1][1] != "072E" {
+		t.Fatalf("unexpected rows: %q", rows)
+	}
+}
+
+func TestReadAllXLSXReadsFirstSheet(t *testing.T) {
+	f := excelize.NewFile()
+	if err := f.SetSheetRow("Sheet1", "A1", &[]any{"NAVE", "VIAJE"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetSheetRow("Sheet1", "A2", &[]any{"NYK DENEB", "072E"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadAll(&buf, FormatXLSX)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "NYK DENEB" {
+		t.Fatalf("unexpected rows: %q", rows)
+	}
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	if f, err := FormatFromFilename("manifest.XLSX"); err != nil || f != FormatXLSX {
+		t.Fatalf("expected xlsx, got %q, %v", f, err)
+	}
+	if _, err := FormatFromFilename("manifest.pdf"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range [][]string{{"NAVE", "VIAJE"}, {"NYK DENEB", "072E"}} {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := ReadAll(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if len(rows) != 2 || rows[1][1] != "072E" {
+			t.Fatalf("%s: unexpected rows: %q", format, rows)
+		}
+	}
+}
+
+func TestWriterNeutralizesFormulas(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow([]string{"=HYPERLINK(\"http://x\")", "+507", "-1", "@SUM(A1)", "\tTAB", "NYK DENEB", ""}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := ReadAll(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		// XLSX cells are string-typed, so only CSV needs the apostrophe.
+		want := []string{"=HYPERLINK(\"http://x\")", "+507", "-1", "@SUM(A1)", "\tTAB", "NYK DENEB"}
+		if format == FormatCSV {
+			want = []string{"'=HYPERLINK(\"http://x\")", "'+507", "'-1", "'@SUM(A1)", "'\tTAB", "NYK DENEB"}
+		}
+		if len(rows) != 1 || len(rows[0]) < len(want) {
+			t.Fatalf("%s: unexpected rows: %q", format, rows)
+		}
+		for i, v := range want {
+			if rows[0][i] != v {
+				t.Errorf("%s: cell %d = %q, want %q", format, i, rows[0][i], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package unlocode provides the UN/LOCODE port reference data used to normalize
+// puerto_descargue. The embedded dataset covers the main container ports of the Americas; rows
+// follow the UN/LOCODE code list (locode, name with diacritics, ISO 3166 country).
+package unlocode
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+//go:embed americas.csv
+var americasCSV string
+
+// countryNames are the Spanish country names used in display names.
+var countryNames = map[string]string{
+	"AG": "Antigua y Barbuda", "AR": "Argentina", "AW": "Aruba", "BB": "Barbados", "BR": "Brasil",
+	"BS": "Bahamas", "BZ": "Belice", "CA": "Canadá", "CL": "Chile", "CO": "Colombia", "CR": "Costa Rica",
+	"CU": "Cuba", "CW": "Curazao", "DO": "República Dominicana", "EC": "Ecuador", "GF": "Guayana Francesa",
+	"GP": "Guadalupe", "GT": "Guatemala", "GY": "Guyana", "HN": "Honduras", "HT": "Haití", "JM": "Jamaica",
+	"MQ": "Martinica", "MX": "México", "NI": "Nicaragua", "PA": "Panamá", "PE": "Perú", "PR": "Puerto Rico",
+	"PY": "Paraguay", "SR": "Surinam", "SV": "El Salvador", "TT": "Trinidad y Tobago", "US": "Estados Unidos",
+	"UY": "Uruguay", "VE": "Venezuela",
+}
+
+// Directory looks ports up by code and name. It is read-only and safe for concurrent use.
+type Directory struct {
+	ports         []entry
+	byCode        map[string]int
+	preferCountry string
+}
+
+type entry struct {
+	port    domain.Port
+	nameKey string
+}
+
+// Load parses the embedded dataset. preferCountry (e.g. PA) settles names shared by ports of
+// several countries, such as Manzanillo, when puerto_descargue does not name the country.
+func Load(preferCountry string) (*Directory, error) {
+	rows, err := csv.NewReader(strings.NewReader(americasCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unlocode: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unlocode: empty dataset")
+	}
+	d := &Directory{byCode: make(map[string]int, len(rows)), preferCountry: strings.ToUpper(strings.TrimSpace(preferCountry))}
+	for i, row := range rows[1:] {
+		if len(row) != 3 || len(row[0]) != 5 || row[0][:2] != row[2] {
+			return nil, fmt.Errorf("unlocode: invalid row %d: %v", i+2, row)
+		}
+		if _, dup := d.byCode[row[0]]; dup {
+			return nil, fmt.Errorf("unlocode: duplicate code %s", row[0])
+		}
+		d.byCode[row[0]] = len(d.ports)
+		d.ports = append(d.ports, entry{
+			port:    domain.Port{Code: row[0], Name: row[1], Country: row[2], CountryName: countryNames[row[2]]},
+			nameKey: normalize(row[1]),
+		})
+	}
+	return d, nil
+}
+
+// Lookup finds a port by code, ignoring case and spaces ("pa blb" finds PABLB).
+func (d *Directory) Lookup(code string) (domain.Port, bool) {
+	i, ok := d.byCode[strings.ReplaceAll(normalize(code), " ", "")]
+	if !ok {
+		return domain.Port{}, false
+	}
+	return d.ports[i].port, true
+}
+
+// Search returns up to limit ports whose code starts with query or whose name has a word
+// starting with it, exact codes first and then by name. country, when set, keeps only its ports.
+func (d *Directory) Search(query, country string, limit int) []domain.Port {
+	q := normalize(query)
+	country = strings.ToUpper(strings.TrimSpace(country))
+	compact := strings.ReplaceAll(q, " ", "")
+
+	type hit struct {
+		port  domain.Port
+		exact bool
+	}
+	hits := make([]hit, 0)
+	for _, e := range d.ports {
+		if country != "" && e.port.Country != country {
+			continue
+		}
+		if q == "" || strings.HasPrefix(e.port.Code, compact) ||
+			strings.HasPrefix(e.nameKey, q) || strings.Contains(e.nameKey, " "+q) {
+			hits = append(hits, hit{port: e.port, exact: e.port.Code == compact})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].exact != hits[j].exact {
+			return hits[i].exact
+		}
+		return normalize(hits[i].port.Name) < normalize(hits[j].port.Name)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	ports := make([]domain.Port, 0, len(hits))
+	for _, h := range hits {
+		ports = append(ports, h.port)
+	}
+	return ports
+}
+
+// Resolve maps a free-text puerto_descargue to a port. It accepts a code ("PABLB", "PA BLB"), a
+// code followed by text ("PABLB - Balboa") or text containing a port name as whole words
+// ("Puerto de Balboa"). When the name belongs to several ports, a country named in the text
+// (code or name) or else the preferred country decides; otherwise nothing is resolved.
+func (d *Directory) Resolve(text string) (domain.Port, bool) {
+	key := normalize(text)
+	if key == "" {
+		return domain.Port{}, false
+	}
+	if p, ok := d.Lookup(key); ok {
+		return p, true
+	}
+	if first, _, _ := strings.Cut(key, " "); len(first) == 5 {
+		if p, ok := d.Lookup(first); ok {
+			return p, true
+		}
+	}
+
+	padded := " " + key + " "
+	var matches []entry
+	for _, e := range d.ports {
+		if strings.Contains(padded, " "+e.nameKey+" ") {
+			matches = append(matches, e)
+		}
+	}
+	// A port named like its country (Panamá) loses to any other name, so display names such as
+	// "Colón, Panamá" resolve to Colón.
+	if len(matches) > 1 {
+		others := make([]entry, 0, len(matches))
+		for _, e := range matches {
+			if e.nameKey != normalize(e.port.CountryName) {
+				others = append(others, e)
+			}
+		}
+		if len(others) > 0 {
+			matches = others
+		}
+	}
+	var candidates []domain.Port
+	bestLen := 0
+	for _, e := range matches {
+		switch {
+		case len(e.nameKey) > bestLen:
+			candidates, bestLen = []domain.Port{e.port}, len(e.nameKey)
+		case len(e.nameKey) == bestLen:
+			candidates = append(candidates, e.port)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	if len(candidates) == 0 {
+		return domain.Port{}, false
+	}
+
+	if named := filterCountry(candidates, func(p domain.Port) bool {
+		return strings.Contains(padded, " "+p.Country+" ") || strings.Contains(padded, " "+normalize(p.CountryName)+" ")
+	}); len(named) > 0 {
+		candidates = named
+	}
+	if len(candidates) > 1 && d.preferCountry != "" {
+		candidates = filterCountry(candidates, func(p domain.Port) bool { return p.Country == d.preferCountry })
+	}
+	if len(candidates) != 1 {
+		return domain.Port{}, false
+	}
+	return candidates[0], true
+}
+
+func filterCountry(ports []domain.Port, keep func(domain.Port) bool) []domain.Port {
+	out := make([]domain.Port, 0, len(ports))
+	for _, p := range ports {
+		if keep(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// normalize uppercases value, drops diacritics and reduces anything that is not a letter or
+// digit to single spaces, so "Lázaro Cárdenas, Mich." becomes "LAZARO CARDENAS MICH".
+func normalize(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if folded, ok := foldDiacritic[r]; ok {
+			r = folded
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+var foldDiacritic = map[rune]rune{
+	'Á': 'A', 'À': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A',
+	'É': 'E', 'È': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Í': 'I', 'Ì': 'I', 'Î': 'I', 'Ï': 'I',
+	'Ó': 'O', 'Ò': 'O', 'Ô': 'O', 'Õ': 'O', 'Ö': 'O',
+	'Ú': 'U', 'Ù': 'U', 'Û': 'U', 'Ü': 'U',
+	'Ç': 'C', 'Ñ': 'N',
+}
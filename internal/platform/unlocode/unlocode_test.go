@@ -0,0 +1,78 @@
+package unlocode
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	d, err := Load("PA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"PABLB":                    "PABLB",
+		"pa blb":                   "PABLB",
+		"PACTB - Cristobal":        "PACTB",
+		"Puerto de Balboa":         "PABLB",
+		"CRISTÓBAL":                "PACTB",
+		"Colón, Panamá":            "PAONX",
+		"Panamá":                   "PAPTY",
+		"Manzanillo":               "PAMIT",
+		"Manzanillo, México":       "MXZLO",
+		"MANZANILLO MX":            "MXZLO",
+		"Lazaro Cardenas, Mich.":   "MXLZC",
+		"Sao Francisco do Sul":     "BRSFS",
+		"Vacamonte":                "PAVAC",
+		"Muelle 7 - Rodman":        "PAROD",
+		"TERMINAL DESCONOCIDA XYZ": "",
+		"":                         "",
+	}
+	for text, want := range cases {
+		p, ok := d.Resolve(text)
+		if want == "" {
+			if ok {
+				t.Errorf("%q: expected no port, got %s", text, p.Code)
+			}
+			continue
+		}
+		if !ok || p.Code != want {
+			t.Errorf("%q: expected %s, got %s (%v)", text, want, p.Code, ok)
+		}
+	}
+
+	p, _ := d.Resolve("balboa")
+	if p.DisplayName() != "Balboa, Panamá" {
+		t.Fatalf("unexpected display name %q", p.DisplayName())
+	}
+	if back, ok := d.Resolve(p.DisplayName()); !ok || back.Code != p.Code {
+		t.Fatalf("display name does not resolve back: %+v", back)
+	}
+}
+
+func TestResolveAmbiguousWithoutPreference(t *testing.T) {
+	d, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := d.Resolve("Manzanillo"); ok {
+		t.Fatalf("expected ambiguous name to stay unresolved, got %s", p.Code)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	d, err := Load("PA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports := d.Search("man", "", 10)
+	if len(ports) < 3 || ports[0].Name != "Manaus" {
+		t.Fatalf("unexpected results: %+v", ports)
+	}
+	if ports := d.Search("manz", "mx", 10); len(ports) != 1 || ports[0].Code != "MXZLO" {
+		t.Fatalf("expected country filter, got %+v", ports)
+	}
+	if ports := d.Search("PABLB", "", 10); len(ports) != 1 || ports[0].Name != "Balboa" {
+		t.Fatalf("expected code match, got %+v", ports)
+	}
+	if ports := d.Search("", "", 5); len(ports) != 5 {
+		t.Fatalf("expected limit to apply, got %d", len(ports))
+	}
+}
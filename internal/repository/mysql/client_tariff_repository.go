@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type ClientTariffRepository struct {
+	db *sql.DB
+}
+
+func NewClientTariffRepository(db *sql.DB) *ClientTariffRepository {
+	return &ClientTariffRepository{db: db}
+}
+
+func (r *ClientTariffRepository) List(ctx context.Context) (tariffs []domain.ClientTariff, err error) {
+	const q = `
+SELECT id, cliente, rama, container_type, default_dias_libre, max_dias_libre, valid_from, valid_to
+FROM client_tariffs
+ORDER BY cliente, valid_from`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	tariffs = make([]domain.ClientTariff, 0)
+	for rows.Next() {
+		var t domain.ClientTariff
+		var validTo sql.NullTime
+		if err := rows.Scan(&t.ID, &t.Cliente, &t.Rama, &t.ContainerType, &t.DefaultDiasLibre, &t.MaxDiasLibre, &t.ValidFrom, &validTo); err != nil {
+			return nil, err
+		}
+		if validTo.Valid {
+			t.ValidTo = validTo.Time
+		}
+		tariffs = append(tariffs, t)
+	}
+	return tariffs, rows.Err()
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestClientTariffListReadsOpenEndedValidity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewClientTariffRepository(db)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM client_tariffs").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "cliente", "rama", "container_type", "default_dias_libre", "max_dias_libre", "valid_from", "valid_to"}).
+			AddRow(int64(1), "CAPITAL PACIFICO, S.A.", "", "", 5, 10, from, nil).
+			AddRow(int64(2), "CAPITAL PACIFICO, S.A.", "nacional", "45G1", 7, 14, from, to))
+	mock.ExpectClose()
+
+	tariffs, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tariffs) != 2 || !tariffs[0].ValidTo.IsZero() || !tariffs[1].ValidTo.Equal(to) || tariffs[1].MaxDiasLibre != 14 {
+		t.Fatalf("unexpected tariffs: %+v", tariffs)
+	}
+}
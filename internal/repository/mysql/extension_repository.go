@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+type ExtensionRepository struct {
+	db *sql.DB
+}
+
+func NewExtensionRepository(db *sql.DB) *ExtensionRepository {
+	return &ExtensionRepository{db: db}
+}
+
+// Extend amends the record and stores the extension in one transaction, so a failed insert
+// leaves the pass with its previous free time.
+func (r *ExtensionRepository) Extend(ctx context.Context, record domain.Record, expectedVersion int, change domain.RecordVersion, ext domain.Extension) (id int64, err error) {
+	const q = `
+INSERT INTO record_extensions (record_id, days, reason, approved_by, requested_by, previous_until, new_until, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = amendRecord(ctx, tx, record, expectedVersion, change); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, q,
+		ext.RecordID,
+		ext.Days,
+		ext.Reason,
+		ext.ApprovedBy,
+		ext.RequestedBy,
+		ext.PreviousUntil,
+		ext.NewUntil,
+		ext.CreatedAt,
+	)
+	if err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1452 {
+			return 0, domain.ErrNotFound
+		}
+		return 0, err
+	}
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *ExtensionRepository) ListByRecordID(ctx context.Context, recordID int64) (extensions []domain.Extension, err error) {
+	const q = `
+SELECT id, record_id, days, reason, approved_by, requested_by, previous_until, new_until, created_at
+FROM record_extensions
+WHERE record_id = ?
+ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, q, recordID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	extensions = make([]domain.Extension, 0)
+	for rows.Next() {
+		var ext domain.Extension
+		if err := rows.Scan(
+			&ext.ID,
+			&ext.RecordID,
+			&ext.Days,
+			&ext.Reason,
+			&ext.ApprovedBy,
+			&ext.RequestedBy,
+			&ext.PreviousUntil,
+			&ext.NewUntil,
+			&ext.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions, rows.Err()
+}
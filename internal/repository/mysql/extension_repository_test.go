@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestExtensionExtendCommitsRecordAndExtensionTogether(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewExtensionRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM record_containers").WithArgs(int64(10)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO record_versions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_extensions").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	id, err := repo.Extend(context.Background(), domain.Record{ID: 10, DiasLibre: 7}, 2,
+		domain.RecordVersion{RecordID: 10, Version: 3}, domain.Extension{RecordID: 10, Days: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 7 {
+		t.Fatalf("expected extension id 7, got %d", id)
+	}
+}
+
+func TestExtensionExtendRollsBackWhenInsertFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewExtensionRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM record_containers").WithArgs(int64(10)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO record_versions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_extensions").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	_, err = repo.Extend(context.Background(), domain.Record{ID: 10, DiasLibre: 7}, 2,
+		domain.RecordVersion{RecordID: 10, Version: 3}, domain.Extension{RecordID: 10, Days: 4})
+	if err == nil {
+		t.Fatal("expected the insert error")
+	}
+}
+
+func TestExtensionExtendUnknownRecordIsNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewExtensionRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM record_containers").WithArgs(int64(99)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO record_versions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_extensions").WillReturnError(&mysql.MySQLError{Number: 1452})
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	_, err = repo.Extend(context.Background(), domain.Record{ID: 99}, 1,
+		domain.RecordVersion{RecordID: 99, Version: 2}, domain.Extension{RecordID: 99, Days: 2})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestExtensionListByRecordID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewExtensionRepository(db)
+	prev := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	next := prev.AddDate(0, 0, 5)
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "record_id", "days", "reason", "approved_by", "requested_by", "previous_until", "new_until", "created_at"}).
+		AddRow(int64(1), int64(10), 5, "negociado con cliente", "gerente", "user-1", prev, next, at)
+	mock.ExpectQuery("SELECT id, record_id, days").WithArgs(int64(10)).WillReturnRows(rows)
+	mock.ExpectClose()
+
+	exts, err := repo.ListByRecordID(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exts) != 1 || exts[0].Days != 5 || !exts[0].NewUntil.Equal(next) {
+		t.Fatalf("unexpected extensions: %+v", exts)
+	}
+}
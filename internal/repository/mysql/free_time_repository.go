@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type FreeTimeRepository struct {
+	db *sql.DB
+}
+
+func NewFreeTimeRepository(db *sql.DB) *FreeTimeRepository {
+	return &FreeTimeRepository{db: db}
+}
+
+func (r *FreeTimeRepository) ListHolidays(ctx context.Context) (holidays []domain.Holiday, err error) {
+	const q = `
+SELECT terminal_code, fecha, nombre, yearly
+FROM terminal_holidays
+ORDER BY fecha`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	holidays = make([]domain.Holiday, 0)
+	for rows.Next() {
+		var h domain.Holiday
+		if err := rows.Scan(&h.TerminalCode, &h.Date, &h.Name, &h.Yearly); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
+
+func (r *FreeTimeRepository) ListClientPolicies(ctx context.Context) (policies []domain.ClientFreeTimePolicy, err error) {
+	const q = `
+SELECT cliente, free_time_policy
+FROM client_free_time_policies
+ORDER BY cliente`
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	policies = make([]domain.ClientFreeTimePolicy, 0)
+	for rows.Next() {
+		var p domain.ClientFreeTimePolicy
+		if err := rows.Scan(&p.Cliente, &p.Policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
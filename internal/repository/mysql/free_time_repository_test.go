@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func TestFreeTimeListHolidays(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewFreeTimeRepository(db)
+	mock.ExpectQuery("FROM terminal_holidays").WillReturnRows(
+		sqlmock.NewRows([]string{"terminal_code", "fecha", "nombre", "yearly"}).
+			AddRow("", time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC), "Día de los Mártires", true).
+			AddRow("PAMIT", time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC), "Aniversario", false))
+	mock.ExpectClose()
+
+	holidays, err := repo.ListHolidays(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(holidays) != 2 || !holidays[0].Yearly || holidays[1].TerminalCode != "PAMIT" {
+		t.Fatalf("unexpected holidays: %+v", holidays)
+	}
+}
+
+func TestFreeTimeListClientPolicies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewFreeTimeRepository(db)
+	mock.ExpectQuery("FROM client_free_time_policies").WillReturnRows(
+		sqlmock.NewRows([]string{"cliente", "free_time_policy"}).AddRow("CAPITAL PACIFICO, S.A.", "business_days"))
+	mock.ExpectClose()
+
+	policies, err := repo.ListClientPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(policies) != 1 || policies[0].Policy != domain.FreeTimeBusinessDays {
+		t.Fatalf("unexpected policies: %+v", policies)
+	}
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, req domain.IdempotentRequest) error {
+	// An expired entry no longer protects its key; drop it so the key can be reused.
+	const purgeQ = `
+DELETE FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ? AND expires_at <= ?`
+	const insertQ = `
+INSERT INTO idempotency_keys (subject, idempotency_key, request_hash, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?)`
+
+	if _, err := r.db.ExecContext(ctx, purgeQ, req.Subject, req.Key, req.CreatedAt); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, insertQ, req.Subject, req.Key, req.RequestHash, req.CreatedAt, req.ExpiresAt)
+	if err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Find(ctx context.Context, subject, key string, now time.Time) (domain.IdempotentRequest, error) {
+	const q = `
+SELECT subject, idempotency_key, request_hash, status_code, content_type, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ? AND expires_at > ?`
+
+	var req domain.IdempotentRequest
+	var status sql.NullInt64
+	err := r.db.QueryRowContext(ctx, q, subject, key, now).Scan(
+		&req.Subject,
+		&req.Key,
+		&req.RequestHash,
+		&status,
+		&req.ContentType,
+		&req.Body,
+		&req.CreatedAt,
+		&req.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.IdempotentRequest{}, domain.ErrNotFound
+		}
+		return domain.IdempotentRequest{}, err
+	}
+	req.StatusCode = int(status.Int64)
+	return req, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, subject, key string, statusCode int, contentType string, body []byte) error {
+	const q = `
+UPDATE idempotency_keys
+SET status_code = ?, content_type = ?, response_body = ?
+WHERE subject = ? AND idempotency_key = ? AND status_code IS NULL`
+
+	_, err := r.db.ExecContext(ctx, q, statusCode, contentType, body, subject, key)
+	return err
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, subject, key string) error {
+	const q = `
+DELETE FROM idempotency_keys
+WHERE subject = ? AND idempotency_key = ? AND status_code IS NULL`
+
+	_, err := r.db.ExecContext(ctx, q, subject, key)
+	return err
+}
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIdempotencyReserveTakenKeyIsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewIdempotencyRepository(db)
+	now := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM idempotency_keys").WithArgs("gate-1", "scan-42", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs("gate-1", "scan-42", "abc", now, now.Add(time.Hour)).
+		WillReturnError(&mysql.MySQLError{Number: 1062})
+	mock.ExpectClose()
+
+	err = repo.Reserve(context.Background(), domain.IdempotentRequest{
+		Subject: "gate-1", Key: "scan-42", RequestHash: "abc", CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestIdempotencyFindPendingHasNoStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewIdempotencyRepository(db)
+	now := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"subject", "idempotency_key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at"}).
+		AddRow("gate-1", "scan-42", "abc", nil, "", nil, now, now.Add(time.Hour))
+	mock.ExpectQuery("SELECT subject, idempotency_key").WithArgs("gate-1", "scan-42", now).WillReturnRows(rows)
+	mock.ExpectClose()
+
+	req, err := repo.Find(context.Background(), "gate-1", "scan-42", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.StatusCode != 0 || req.RequestHash != "abc" {
+		t.Fatalf("unexpected entry: %+v", req)
+	}
+}
@@ -3,7 +3,10 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/example/validacion-pases/internal/domain"
 	"github.com/go-sql-driver/mysql"
@@ -17,16 +20,82 @@ func NewRecordRepository(db *sql.DB) *RecordRepository {
 	return &RecordRepository{db: db}
 }
 
-func (r *RecordRepository) Insert(ctx context.Context, record domain.Record) (int64, error) {
-	const q = `
+const insertRecordQuery = `
 INSERT INTO records (
     emision, nave, viaje, cliente, booking, rama, contenedor,
-    puerto_descargue, libre_retencion_hasta, dias_libre, transportista,
-    titulo_terminal, usuario_firma, created_at
+    puerto_descargue, puerto_locode, puerto_nombre, fecha_real, libre_retencion_hasta, dias_libre,
+    free_time_policy, transportista, titulo_terminal, usuario_firma, status, max_uses, created_at
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+const insertContainerQuery = `
+INSERT INTO record_containers (record_id, line_no, booking, viaje, contenedor_serie, codigo_iso, cantidad)
+VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Insert stores the record and its container lines in one transaction.
+func (r *RecordRepository) Insert(ctx context.Context, record domain.Record) (id int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if id, err = insertRecord(ctx, tx, record); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// InsertBatch inserts records in one transaction. Each record is written under a savepoint, so a
+// duplicate key undoes only that record's rows; conflicting records are reported and the rest of
+// the batch still commits.
+func (r *RecordRepository) InsertBatch(ctx context.Context, records []domain.Record) (results []domain.BatchInsertResult, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	results = make([]domain.BatchInsertResult, 0, len(records))
+	for _, record := range records {
+		if _, err = tx.ExecContext(ctx, "SAVEPOINT batch_record"); err != nil {
+			return nil, err
+		}
+		id, insertErr := insertRecord(ctx, tx, record)
+		if insertErr != nil {
+			if !errors.Is(insertErr, domain.ErrConflict) {
+				return nil, insertErr
+			}
+			if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_record"); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, domain.BatchInsertResult{ID: id, Err: insertErr})
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-	res, err := r.db.ExecContext(ctx, q,
+// insertRecord writes the record row followed by its container lines.
+func insertRecord(ctx context.Context, db execer, record domain.Record) (int64, error) {
+	res, err := db.ExecContext(ctx, insertRecordQuery,
 		record.Emision,
 		record.Nave,
 		record.Viaje,
@@ -35,37 +104,75 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 		record.Rama,
 		record.Contenedor,
 		record.PuertoDescargue,
+		record.PuertoLocode,
+		record.PuertoNombre,
+		nullableDate(record.FechaReal),
 		record.LibreRetencionHasta,
 		record.DiasLibre,
+		freeTimePolicyOrCalendar(record.FreeTimePolicy),
 		record.Transportista,
 		record.TituloTerminal,
 		record.UsuarioFirma,
+		statusOrIssued(record.Status),
+		nullableMaxUses(record.MaxUses),
 		record.CreatedAt,
 	)
 	if err != nil {
-		var me *mysql.MySQLError
-		if errors.As(err, &me) && me.Number == 1062 {
-			return 0, domain.ErrConflict
-		}
-		return 0, err
+		return 0, conflictOr(err)
 	}
 
 	id, err := res.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
+	if err := insertContainers(ctx, db, id, record); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
-func (r *RecordRepository) FindByID(ctx context.Context, id int64) (domain.Record, error) {
-	const q = `
-SELECT id, emision, nave, viaje, cliente, booking, rama, contenedor, puerto_descargue,
-       libre_retencion_hasta, dias_libre, transportista, titulo_terminal, usuario_firma, created_at
-FROM records
-WHERE id = ?`
+func insertContainers(ctx context.Context, db execer, recordID int64, record domain.Record) error {
+	for i, line := range record.Containers {
+		_, err := db.ExecContext(ctx, insertContainerQuery,
+			recordID,
+			i+1,
+			record.Booking,
+			record.Viaje,
+			line.Serie,
+			line.CodigoISO,
+			line.Cantidad,
+		)
+		if err != nil {
+			return conflictOr(err)
+		}
+	}
+	return nil
+}
+
+// conflictOr maps MySQL duplicate-key errors to domain.ErrConflict.
+func conflictOr(err error) error {
+	var me *mysql.MySQLError
+	if errors.As(err, &me) && me.Number == 1062 {
+		return domain.ErrConflict
+	}
+	return err
+}
 
+const recordColumns = `
+       id, emision, nave, viaje, cliente, booking, rama, contenedor, puerto_descargue,
+       puerto_locode, puerto_nombre, fecha_real, libre_retencion_hasta, dias_libre, free_time_policy, transportista,
+       titulo_terminal, usuario_firma,
+       status, status_updated_at, max_uses, use_count, version, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner) (domain.Record, error) {
 	var rec domain.Record
-	err := r.db.QueryRowContext(ctx, q, id).Scan(
+	var fechaReal, statusUpdatedAt sql.NullTime
+	var maxUses sql.NullInt64
+	err := row.Scan(
 		&rec.ID,
 		&rec.Emision,
 		&rec.Nave,
@@ -75,19 +182,410 @@ WHERE id = ?`
 		&rec.Rama,
 		&rec.Contenedor,
 		&rec.PuertoDescargue,
+		&rec.PuertoLocode,
+		&rec.PuertoNombre,
+		&fechaReal,
 		&rec.LibreRetencionHasta,
 		&rec.DiasLibre,
+		&rec.FreeTimePolicy,
 		&rec.Transportista,
 		&rec.TituloTerminal,
 		&rec.UsuarioFirma,
+		&rec.Status,
+		&statusUpdatedAt,
+		&maxUses,
+		&rec.UseCount,
+		&rec.Version,
 		&rec.CreatedAt,
 	)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	if fechaReal.Valid {
+		rec.FechaReal = fechaReal.Time
+	}
+	if statusUpdatedAt.Valid {
+		rec.StatusUpdatedAt = statusUpdatedAt.Time
+	}
+	rec.MaxUses = int(maxUses.Int64)
+	return rec, nil
+}
+
+func (r *RecordRepository) FindByID(ctx context.Context, id int64) (domain.Record, error) {
+	const q = `SELECT` + recordColumns + `
+FROM records
+WHERE id = ?`
+
+	rec, err := scanRecord(r.db.QueryRowContext(ctx, q, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Record{}, domain.ErrNotFound
 		}
 		return domain.Record{}, err
 	}
+	records := []domain.Record{rec}
+	if err := r.loadContainers(ctx, records); err != nil {
+		return domain.Record{}, err
+	}
+	return records[0], nil
+}
+
+// loadContainers fills the container lines of records with a single query.
+func (r *RecordRepository) loadContainers(ctx context.Context, records []domain.Record) (err error) {
+	if len(records) == 0 {
+		return nil
+	}
+	byID := make(map[int64]int, len(records))
+	args := make([]any, 0, len(records))
+	for i, rec := range records {
+		byID[rec.ID] = i
+		args = append(args, rec.ID)
+	}
+	q := `
+SELECT record_id, contenedor_serie, codigo_iso, cantidad
+FROM record_containers
+WHERE record_id IN (?` + strings.Repeat(", ?", len(records)-1) + `)
+ORDER BY record_id, line_no`
 
-	return rec, nil
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var recordID int64
+		var line domain.ContainerLine
+		if err := rows.Scan(&recordID, &line.Serie, &line.CodigoISO, &line.Cantidad); err != nil {
+			return err
+		}
+		if i, ok := byID[recordID]; ok {
+			records[i].Containers = append(records[i].Containers, line)
+		}
+	}
+	return rows.Err()
+}
+
+// recordFilterWhere builds the WHERE clause and arguments for the text and time filters of filter.
+func recordFilterWhere(filter domain.RecordFilter) (conds []string, args []any) {
+	eq := func(column, value string) {
+		if value != "" {
+			conds = append(conds, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	between := func(column string, from, to time.Time) {
+		if !from.IsZero() {
+			conds = append(conds, column+" >= ?")
+			args = append(args, from)
+		}
+		if !to.IsZero() {
+			conds = append(conds, column+" < ?")
+			args = append(args, to)
+		}
+	}
+
+	eq("booking", filter.Booking)
+	eq("viaje", filter.Viaje)
+	eq("nave", filter.Nave)
+	eq("cliente", filter.Cliente)
+	if filter.Contenedor != "" {
+		// A pass with several containers matches any of its serials as well as its display string.
+		conds = append(conds, "(contenedor = ? OR id IN (SELECT record_id FROM record_containers WHERE contenedor_serie = ?))")
+		args = append(args, filter.Contenedor, filter.Contenedor)
+	}
+	eq("rama", filter.Rama)
+	eq("puerto_descargue", filter.PuertoDescargue)
+	eq("puerto_locode", filter.PuertoLocode)
+	eq("titulo_terminal", filter.TituloTerminal)
+	eq("usuario_firma", filter.UsuarioFirma)
+	between("emision", filter.EmisionFrom, filter.EmisionTo)
+	between("created_at", filter.CreatedFrom, filter.CreatedTo)
+	return conds, args
+}
+
+func (r *RecordRepository) Search(ctx context.Context, filter domain.RecordFilter) (records []domain.Record, err error) {
+	conds, args := recordFilterWhere(filter)
+	if filter.AfterID > 0 {
+		conds = append(conds, "id < ?")
+		args = append(args, filter.AfterID)
+	}
+
+	q := `SELECT` + recordColumns + `
+FROM records`
+	if len(conds) > 0 {
+		q += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	q += "\nORDER BY id DESC\nLIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	records = make([]domain.Record, 0)
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadContainers(ctx, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *RecordRepository) Stream(ctx context.Context, filter domain.RecordFilter, fn func(domain.Record) error) (err error) {
+	conds, args := recordFilterWhere(filter)
+	q := `SELECT` + recordColumns + `
+FROM records`
+	if len(conds) > 0 {
+		q += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	q += "\nORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type fieldChangeJSON struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+func (r *RecordRepository) Amend(ctx context.Context, record domain.Record, expectedVersion int, change domain.RecordVersion) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = amendRecord(ctx, tx, record, expectedVersion, change); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// amendRecord applies an optimistic-locked update, rewrites the container lines and appends the
+// version row. Callers own the transaction so other writes can commit or roll back with it.
+func amendRecord(ctx context.Context, tx execer, record domain.Record, expectedVersion int, change domain.RecordVersion) error {
+	const updateQ = `
+UPDATE records
+SET nave = ?, viaje = ?, cliente = ?, booking = ?, rama = ?, contenedor = ?, puerto_descargue = ?,
+    puerto_locode = ?, puerto_nombre = ?, fecha_real = ?, libre_retencion_hasta = ?, dias_libre = ?,
+    transportista = ?, titulo_terminal = ?,
+    version = version + 1
+WHERE id = ? AND version = ?`
+	const historyQ = `
+INSERT INTO record_versions (record_id, version, changed_by, changed_at, changes)
+VALUES (?, ?, ?, ?, ?)`
+
+	changes := make([]fieldChangeJSON, 0, len(change.Changes))
+	for _, c := range change.Changes {
+		changes = append(changes, fieldChangeJSON(c))
+	}
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, updateQ,
+		record.Nave,
+		record.Viaje,
+		record.Cliente,
+		record.Booking,
+		record.Rama,
+		record.Contenedor,
+		record.PuertoDescargue,
+		record.PuertoLocode,
+		record.PuertoNombre,
+		nullableDate(record.FechaReal),
+		record.LibreRetencionHasta,
+		record.DiasLibre,
+		record.Transportista,
+		record.TituloTerminal,
+		record.ID,
+		expectedVersion,
+	)
+	if err != nil {
+		return conflictOr(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrPreconditionFailed
+	}
+
+	// Container lines carry booking and viaje for the uniqueness check, so they are rewritten on
+	// every amendment.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM record_containers WHERE record_id = ?", record.ID); err != nil {
+		return err
+	}
+	if err := insertContainers(ctx, tx, record.ID, record); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, historyQ, record.ID, change.Version, change.ChangedBy, change.ChangedAt, payload); err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			return domain.ErrPreconditionFailed
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RecordRepository) ListVersions(ctx context.Context, recordID int64) (versions []domain.RecordVersion, err error) {
+	const q = `
+SELECT record_id, version, changed_by, changed_at, changes
+FROM record_versions
+WHERE record_id = ?
+ORDER BY version`
+
+	rows, err := r.db.QueryContext(ctx, q, recordID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	versions = make([]domain.RecordVersion, 0)
+	for rows.Next() {
+		var v domain.RecordVersion
+		var payload []byte
+		if err := rows.Scan(&v.RecordID, &v.Version, &v.ChangedBy, &v.ChangedAt, &payload); err != nil {
+			return nil, err
+		}
+		var changes []fieldChangeJSON
+		if err := json.Unmarshal(payload, &changes); err != nil {
+			return nil, err
+		}
+		for _, c := range changes {
+			v.Changes = append(v.Changes, domain.FieldChange(c))
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *RecordRepository) UpdateStatus(ctx context.Context, id int64, from, to domain.RecordStatus, at time.Time) error {
+	return updateStatus(ctx, r.db, id, from, to, at)
+}
+
+// updateStatus moves the record from status from to to, failing with ErrConflict when another
+// writer changed it first. Callers own the transaction, if any.
+func updateStatus(ctx context.Context, db execer, id int64, from, to domain.RecordStatus, at time.Time) error {
+	const q = `
+UPDATE records
+SET status = ?, status_updated_at = ?
+WHERE id = ? AND status = ?`
+
+	res, err := db.ExecContext(ctx, q, to, at, id, from)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (r *RecordRepository) ConsumeUse(ctx context.Context, id int64, at time.Time) error {
+	// MySQL evaluates single-table SET assignments left to right, so the status check
+	// below already sees the incremented use_count.
+	const q = `
+UPDATE records
+SET use_count = use_count + 1,
+    status = IF(max_uses IS NOT NULL AND use_count >= max_uses, 'used', status),
+    status_updated_at = IF(status = 'used', ?, status_updated_at)
+WHERE id = ? AND status = 'issued' AND (max_uses IS NULL OR use_count < max_uses)`
+
+	res, err := r.db.ExecContext(ctx, q, at, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func nullableMaxUses(maxUses int) sql.NullInt64 {
+	if maxUses <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(maxUses), Valid: true}
+}
+
+// nullableDate stores the empty fecha_real of a legacy pass as NULL.
+func nullableDate(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func statusOrIssued(status domain.RecordStatus) domain.RecordStatus {
+	if status == "" {
+		return domain.StatusIssued
+	}
+	return status
+}
+
+func freeTimePolicyOrCalendar(policy domain.FreeTimePolicy) domain.FreeTimePolicy {
+	if policy == "" {
+		return domain.FreeTimeCalendarDays
+	}
+	return policy
 }
@@ -3,11 +3,13 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestInsertSuccess(t *testing.T) {
@@ -30,16 +32,22 @@ func TestInsertSuccess(t *testing.T) {
 		Cliente:             "CLIENTE 1",
 		Booking:             "BK1",
 		Rama:                "internacional",
-		Contenedor:          "ABCU1234567",
+		Contenedor:          "ABCU1234560",
+		Containers:          []domain.ContainerLine{{Serie: "ABCU1234560", Cantidad: 1}},
 		PuertoDescargue:     "Balboa",
+		PuertoLocode:        "PABLB",
+		PuertoNombre:        "Balboa, Panamá",
+		FechaReal:           now.AddDate(0, 0, -2),
 		LibreRetencionHasta: now,
 		DiasLibre:           2,
 		Transportista:       "",
 		TituloTerminal:      "TERMINAL PACIFICO - BALBOA",
 		UsuarioFirma:        "user-1",
+		Status:              domain.StatusIssued,
 		CreatedAt:           now,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO records").WithArgs(
 		rec.Emision,
 		rec.Nave,
@@ -49,13 +57,23 @@ func TestInsertSuccess(t *testing.T) {
 		rec.Rama,
 		rec.Contenedor,
 		rec.PuertoDescargue,
+		rec.PuertoLocode,
+		rec.PuertoNombre,
+		rec.FechaReal,
 		rec.LibreRetencionHasta,
 		rec.DiasLibre,
+		domain.FreeTimeCalendarDays,
 		rec.Transportista,
 		rec.TituloTerminal,
 		rec.UsuarioFirma,
+		rec.Status,
+		sql.NullInt64{},
 		rec.CreatedAt,
 	).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_containers").
+		WithArgs(int64(1), 1, rec.Booking, rec.Viaje, "ABCU1234560", "", 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 	mock.ExpectClose()
 
 	_, err = repo.Insert(context.Background(), rec)
@@ -88,13 +106,19 @@ func TestFindByIDSuccess(t *testing.T) {
 	lrh := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
 	rows := sqlmock.NewRows([]string{
 		"id", "emision", "nave", "viaje", "cliente", "booking", "rama", "contenedor", "puerto_descargue",
-		"libre_retencion_hasta", "dias_libre", "transportista", "titulo_terminal", "usuario_firma", "created_at",
+		"puerto_locode", "puerto_nombre", "fecha_real", "libre_retencion_hasta", "dias_libre", "free_time_policy", "transportista",
+		"titulo_terminal", "usuario_firma",
+		"status", "status_updated_at", "max_uses", "use_count", "version", "created_at",
 	}).AddRow(
 		int64(10), now, "NYK DENEB", "072E", "CAPITAL PACIFICO, S.A.", "YMLUL160382911", "internacional", "YMLU5374938", "RODMAN",
-		lrh, 17, "", "PANAMA PORTS COMPANY (RODMAN)", "Admin", now,
+		"PAROD", "Rodman, Panamá", lrh.AddDate(0, 0, -17), lrh, 17, "calendar_days", "", "PANAMA PORTS COMPANY (RODMAN)", "Admin", "issued", nil, int64(1), 0, 1, now,
 	)
 
 	mock.ExpectQuery("SELECT id, emision, nave").WithArgs(int64(10)).WillReturnRows(rows)
+	mock.ExpectQuery("FROM record_containers WHERE record_id IN \\(\\?\\)").WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"record_id", "contenedor_serie", "codigo_iso", "cantidad"}).
+			AddRow(int64(10), "YMLU5374938", "", 1).
+			AddRow(int64(10), "MSCU1234566", "", 1))
 	mock.ExpectClose()
 
 	rec, err := repo.FindByID(context.Background(), 10)
@@ -104,4 +128,358 @@ func TestFindByIDSuccess(t *testing.T) {
 	if rec.ID != 10 {
 		t.Fatalf("expected id 10, got %d", rec.ID)
 	}
+	if rec.Status != domain.StatusIssued {
+		t.Fatalf("expected status issued, got %s", rec.Status)
+	}
+	if rec.MaxUses != 1 {
+		t.Fatalf("expected max uses 1, got %d", rec.MaxUses)
+	}
+	if len(rec.Containers) != 2 || rec.Containers[1].Serie != "MSCU1234566" {
+		t.Fatalf("unexpected containers: %+v", rec.Containers)
+	}
+}
+
+func TestFindByIDLegacyPassHasEmptyFechaReal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	now := time.Date(2026, 2, 17, 9, 41, 45, 0, time.UTC)
+	lrh := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "emision", "nave", "viaje", "cliente", "booking", "rama", "contenedor", "puerto_descargue",
+		"puerto_locode", "puerto_nombre", "fecha_real", "libre_retencion_hasta", "dias_libre", "free_time_policy", "transportista",
+		"titulo_terminal", "usuario_firma",
+		"status", "status_updated_at", "max_uses", "use_count", "version", "created_at",
+	}).AddRow(
+		int64(10), now, "NYK DENEB", "072E", "CAPITAL PACIFICO, S.A.", "YMLUL160382911", "internacional", "YMLU5374938", "RODMAN",
+		"PAROD", "Rodman, Panamá", nil, lrh, 17, "calendar_days", "", "PANAMA PORTS COMPANY (RODMAN)", "Admin", "issued", nil, nil, 0, 1, now,
+	)
+	mock.ExpectQuery("SELECT id, emision, nave").WithArgs(int64(10)).WillReturnRows(rows)
+	mock.ExpectQuery("FROM record_containers WHERE record_id IN \\(\\?\\)").WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"record_id", "contenedor_serie", "codigo_iso", "cantidad"}))
+	mock.ExpectClose()
+
+	rec, err := repo.FindByID(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.FechaReal.IsZero() || !rec.BaseFechaReal().Equal(lrh.AddDate(0, 0, -17)) {
+		t.Fatalf("expected NULL fecha_real to be derived on read, got %s", rec.FechaReal)
+	}
+}
+
+func TestConsumeUseExhaustedIsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	at := time.Now().UTC()
+	mock.ExpectExec("UPDATE records\\s+SET use_count = use_count \\+ 1").
+		WithArgs(at, int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	if err := repo.ConsumeUse(context.Background(), 10, at); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpdateStatusStaleIsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	at := time.Now().UTC()
+	mock.ExpectExec("UPDATE records").
+		WithArgs(domain.StatusUsed, at, int64(10), domain.StatusIssued).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	err = repo.UpdateStatus(context.Background(), 10, domain.StatusIssued, domain.StatusUsed, at)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestSearchBuildsKeysetQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	now := time.Date(2026, 2, 17, 9, 41, 45, 0, time.UTC)
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "emision", "nave", "viaje", "cliente", "booking", "rama", "contenedor", "puerto_descargue",
+		"puerto_locode", "puerto_nombre", "fecha_real", "libre_retencion_hasta", "dias_libre", "free_time_policy", "transportista",
+		"titulo_terminal", "usuario_firma",
+		"status", "status_updated_at", "max_uses", "use_count", "version", "created_at",
+	}).AddRow(
+		int64(41), now, "NYK DENEB", "072E", "CAPITAL PACIFICO, S.A.", "YMLUL160382911", "internacional", "YMLU5374938", "RODMAN",
+		"PAROD", "Rodman, Panamá", now, now, 17, "business_days", "", "PANAMA PORTS COMPANY (RODMAN)", "Admin", "issued", nil, nil, 0, 1, now,
+	)
+
+	mock.ExpectQuery(`FROM records WHERE booking = \? AND rama = \? AND emision >= \? AND id < \? ORDER BY id DESC LIMIT \?`).
+		WithArgs("YMLUL160382911", "internacional", from, int64(42), 21).
+		WillReturnRows(rows)
+	mock.ExpectQuery("FROM record_containers").WithArgs(int64(41)).
+		WillReturnRows(sqlmock.NewRows([]string{"record_id", "contenedor_serie", "codigo_iso", "cantidad"}))
+	mock.ExpectClose()
+
+	recs, err := repo.Search(context.Background(), domain.RecordFilter{
+		Booking:     "YMLUL160382911",
+		Rama:        "internacional",
+		EmisionFrom: from,
+		AfterID:     42,
+		Limit:       21,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recs) != 1 || recs[0].ID != 41 || recs[0].MaxUses != 0 {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+}
+
+func TestAmendWritesRecordAndHistoryInOneTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	at := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	lrh := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	rec := domain.Record{
+		ID: 10, Nave: "NYK DENEB", Viaje: "072E", Cliente: "CAPITAL PACIFICO, S.A.", Booking: "YMLUL160382911",
+		Rama: "internacional", Contenedor: "YMLU5374938", PuertoDescargue: "RODMAN", PuertoLocode: "PAROD",
+		PuertoNombre: "Rodman, Panamá", FechaReal: lrh.AddDate(0, 0, -17), LibreRetencionHasta: lrh, DiasLibre: 17,
+		TituloTerminal: "TERMINAL RODMAN", Containers: []domain.ContainerLine{{Serie: "YMLU5374938", Cantidad: 1}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").
+		WithArgs("NYK DENEB", "072E", "CAPITAL PACIFICO, S.A.", "YMLUL160382911", "internacional", "YMLU5374938", "RODMAN",
+			"PAROD", "Rodman, Panamá", lrh.AddDate(0, 0, -17), lrh, 17, "", "TERMINAL RODMAN", int64(10), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM record_containers").WithArgs(int64(10)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO record_containers").
+		WithArgs(int64(10), 1, "YMLUL160382911", "072E", "YMLU5374938", "", 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_versions").
+		WithArgs(int64(10), 2, "user-1", at, []byte(`[{"field":"nave","before":"NYK DENEP","after":"NYK DENEB"}]`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	err = repo.Amend(context.Background(), rec, 1, domain.RecordVersion{
+		RecordID: 10, Version: 2, ChangedBy: "user-1", ChangedAt: at,
+		Changes: []domain.FieldChange{{Field: "nave", Before: "NYK DENEP", After: "NYK DENEB"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAmendStaleVersionRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	err = repo.Amend(context.Background(), domain.Record{ID: 10}, 3, domain.RecordVersion{RecordID: 10, Version: 4})
+	if !errors.Is(err, domain.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestInsertBatchReportsDuplicatesAndCommits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	line := []domain.ContainerLine{{Serie: "YMLU5374938", Cantidad: 1}}
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO records").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("INSERT INTO record_containers").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO records").WillReturnResult(sqlmock.NewResult(8, 1))
+	mock.ExpectExec("INSERT INTO record_containers").WillReturnError(&mysql.MySQLError{Number: 1062})
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO records").WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectExec("INSERT INTO record_containers").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	results, err := repo.InsertBatch(context.Background(), []domain.Record{
+		{Booking: "A", Containers: line}, {Booking: "B", Containers: line}, {Booking: "C", Containers: line},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 || results[0].ID != 7 || !errors.Is(results[1].Err, domain.ErrConflict) || results[2].ID != 9 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestInsertBatchRollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO records").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("SAVEPOINT batch_record").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO records").WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	if _, err := repo.InsertBatch(context.Background(), []domain.Record{{Booking: "A"}, {Booking: "B"}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestStreamVisitsRowsInIDOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	now := time.Date(2026, 2, 17, 9, 41, 45, 0, time.UTC)
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "emision", "nave", "viaje", "cliente", "booking", "rama", "contenedor", "puerto_descargue",
+		"puerto_locode", "puerto_nombre", "fecha_real", "libre_retencion_hasta", "dias_libre", "free_time_policy", "transportista",
+		"titulo_terminal", "usuario_firma",
+		"status", "status_updated_at", "max_uses", "use_count", "version", "created_at",
+	})
+	for _, id := range []int64{41, 42} {
+		rows.AddRow(id, now, "NYK DENEB", "072E", "CAPITAL PACIFICO, S.A.", "YMLUL160382911", "internacional", "YMLU5374938", "RODMAN",
+			"PAROD", "Rodman, Panamá", now, now, 17, "business_days", "", "PANAMA PORTS COMPANY (RODMAN)", "Admin", "issued", nil, nil, 0, 1, now)
+	}
+
+	mock.ExpectQuery(`FROM records WHERE cliente = \? AND titulo_terminal = \? AND emision >= \? AND emision < \? ORDER BY id$`).
+		WithArgs("CAPITAL PACIFICO, S.A.", "PANAMA PORTS COMPANY (RODMAN)", from, to).
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	var ids []int64
+	err = repo.Stream(context.Background(), domain.RecordFilter{
+		Cliente:        "CAPITAL PACIFICO, S.A.",
+		TituloTerminal: "PANAMA PORTS COMPANY (RODMAN)",
+		EmisionFrom:    from,
+		EmisionTo:      to,
+		Limit:          10,
+	}, func(rec domain.Record) error {
+		ids = append(ids, rec.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 41 || ids[1] != 42 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+}
+
+func TestInsertDuplicateContainerRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRecordRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO records").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("INSERT INTO record_containers").WithArgs(int64(7), 1, "BK1", "072E", "YMLU5374938", "", 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO record_containers").WithArgs(int64(7), 2, "BK1", "072E", "MSCU1234566", "", 1).
+		WillReturnError(&mysql.MySQLError{Number: 1062})
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	_, err = repo.Insert(context.Background(), domain.Record{
+		Booking: "BK1",
+		Viaje:   "072E",
+		Containers: []domain.ContainerLine{
+			{Serie: "YMLU5374938", Cantidad: 1},
+			{Serie: "MSCU1234566", Cantidad: 1},
+		},
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
 }
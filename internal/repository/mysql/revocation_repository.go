@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+type RevocationRepository struct {
+	db *sql.DB
+}
+
+func NewRevocationRepository(db *sql.DB) *RevocationRepository {
+	return &RevocationRepository{db: db}
+}
+
+// Revoke updates the record status and stores the revocation in one transaction, so a pass is
+// never left revoked in one table and issued in the other.
+func (r *RevocationRepository) Revoke(ctx context.Context, revocation domain.Revocation, from domain.RecordStatus) (err error) {
+	const q = `
+INSERT INTO record_revocations (record_id, reason, revoked_by, revoked_at)
+VALUES (?, ?, ?, ?)`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = updateStatus(ctx, tx, revocation.RecordID, from, domain.StatusRevoked, revocation.RevokedAt); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, q,
+		revocation.RecordID,
+		revocation.Reason,
+		revocation.RevokedBy,
+		revocation.RevokedAt,
+	)
+	if err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) {
+			switch me.Number {
+			case 1062:
+				return domain.ErrConflict
+			case 1452:
+				return domain.ErrNotFound
+			}
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RevocationRepository) FindByRecordID(ctx context.Context, recordID int64) (domain.Revocation, error) {
+	const q = `
+SELECT record_id, reason, revoked_by, revoked_at
+FROM record_revocations
+WHERE record_id = ?`
+
+	var rev domain.Revocation
+	err := r.db.QueryRowContext(ctx, q, recordID).Scan(
+		&rev.RecordID,
+		&rev.Reason,
+		&rev.RevokedBy,
+		&rev.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Revocation{}, domain.ErrNotFound
+		}
+		return domain.Revocation{}, err
+	}
+	return rev, nil
+}
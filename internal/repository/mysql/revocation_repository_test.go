@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestRevocationRevokeCommitsStatusAndRevocationTogether(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRevocationRepository(db)
+	rev := domain.Revocation{RecordID: 10, Reason: "wrong container", RevokedBy: "supervisor", RevokedAt: time.Now().UTC()}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").
+		WithArgs(domain.StatusRevoked, rev.RevokedAt, rev.RecordID, domain.StatusIssued).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO record_revocations").
+		WithArgs(rev.RecordID, rev.Reason, rev.RevokedBy, rev.RevokedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	if err := repo.Revoke(context.Background(), rev, domain.StatusIssued); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRevocationRevokeRollsBackWhenInsertFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRevocationRepository(db)
+	rev := domain.Revocation{RecordID: 10, Reason: "wrong container", RevokedBy: "supervisor", RevokedAt: time.Now().UTC()}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO record_revocations").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	if err := repo.Revoke(context.Background(), rev, domain.StatusIssued); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRevocationRevokeChangedStatusIsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRevocationRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE records").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	err = repo.Revoke(context.Background(), domain.Revocation{RecordID: 10, Reason: "wrong container"}, domain.StatusIssued)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRevocationFindByRecordIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewRevocationRepository(db)
+	mock.ExpectQuery("SELECT record_id, reason").WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"record_id", "reason", "revoked_by", "revoked_at"}))
+	mock.ExpectClose()
+
+	if _, err := repo.FindByRecordID(context.Background(), 10); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
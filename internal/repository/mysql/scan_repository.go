@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type ScanRepository struct {
+	db *sql.DB
+}
+
+func NewScanRepository(db *sql.DB) *ScanRepository {
+	return &ScanRepository{db: db}
+}
+
+func (r *ScanRepository) Insert(ctx context.Context, scan domain.Scan) error {
+	const q = `
+INSERT INTO record_scans (record_id, outcome, scanner, client_ip, scanned_at)
+VALUES (?, ?, ?, ?, ?)`
+
+	var recordID sql.NullInt64
+	if scan.RecordID > 0 {
+		recordID = sql.NullInt64{Int64: scan.RecordID, Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, q, recordID, scan.Outcome, scan.Scanner, scan.ClientIP, scan.ScannedAt)
+	return err
+}
+
+func (r *ScanRepository) ListByRecordID(ctx context.Context, recordID int64, limit int) (scans []domain.Scan, err error) {
+	const q = `
+SELECT id, record_id, outcome, scanner, client_ip, scanned_at
+FROM record_scans
+WHERE record_id = ?
+ORDER BY scanned_at DESC, id DESC
+LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, q, recordID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	scans = make([]domain.Scan, 0)
+	for rows.Next() {
+		var scan domain.Scan
+		var rid sql.NullInt64
+		if err := rows.Scan(&scan.ID, &rid, &scan.Outcome, &scan.Scanner, &scan.ClientIP, &scan.ScannedAt); err != nil {
+			return nil, err
+		}
+		scan.RecordID = rid.Int64
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
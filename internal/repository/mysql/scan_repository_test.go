@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func TestScanInsertUnresolvedRecordStoresNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewScanRepository(db)
+	at := time.Now().UTC()
+	mock.ExpectExec("INSERT INTO record_scans").
+		WithArgs(sql.NullInt64{}, domain.ScanInvalidSignature, "gate-1", "10.0.0.5", at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectClose()
+
+	err = repo.Insert(context.Background(), domain.Scan{Outcome: domain.ScanInvalidSignature, Scanner: "gate-1", ClientIP: "10.0.0.5", ScannedAt: at})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScanListByRecordID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewScanRepository(db)
+	at := time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "record_id", "outcome", "scanner", "client_ip", "scanned_at"}).
+		AddRow(int64(2), int64(10), "valid", "gate-1", "10.0.0.5", at)
+	mock.ExpectQuery("SELECT id, record_id, outcome").WithArgs(int64(10), 50).WillReturnRows(rows)
+	mock.ExpectClose()
+
+	scans, err := repo.ListByRecordID(context.Background(), 10, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(scans) != 1 || scans[0].Outcome != domain.ScanValid || scans[0].RecordID != 10 {
+		t.Fatalf("unexpected scans: %+v", scans)
+	}
+}
@@ -0,0 +1,214 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type TerminalRepository struct {
+	db *sql.DB
+}
+
+func NewTerminalRepository(db *sql.DB) *TerminalRepository {
+	return &TerminalRepository{db: db}
+}
+
+const terminalColumns = `id, code, title, address, logo_url, free_time_policy, timezone, created_at, updated_at`
+
+func (r *TerminalRepository) List(ctx context.Context) ([]domain.Terminal, error) {
+	const q = `SELECT ` + terminalColumns + `
+FROM terminals
+ORDER BY code`
+	const aliasesQ = `
+SELECT terminal_id, alias
+FROM terminal_aliases
+ORDER BY terminal_id, alias`
+
+	terminals, err := r.queryTerminals(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]int, len(terminals))
+	for i, t := range terminals {
+		byID[t.ID] = i
+	}
+	err = r.queryAliases(ctx, aliasesQ, func(terminalID int64, alias string) {
+		if i, ok := byID[terminalID]; ok {
+			terminals[i].Aliases = append(terminals[i].Aliases, alias)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return terminals, nil
+}
+
+func (r *TerminalRepository) FindByID(ctx context.Context, id int64) (domain.Terminal, error) {
+	const q = `SELECT ` + terminalColumns + `
+FROM terminals
+WHERE id = ?`
+	const aliasesQ = `
+SELECT terminal_id, alias
+FROM terminal_aliases
+WHERE terminal_id = ?
+ORDER BY alias`
+
+	terminals, err := r.queryTerminals(ctx, q, id)
+	if err != nil {
+		return domain.Terminal{}, err
+	}
+	if len(terminals) == 0 {
+		return domain.Terminal{}, domain.ErrNotFound
+	}
+	t := terminals[0]
+	err = r.queryAliases(ctx, aliasesQ, func(_ int64, alias string) {
+		t.Aliases = append(t.Aliases, alias)
+	}, id)
+	if err != nil {
+		return domain.Terminal{}, err
+	}
+	return t, nil
+}
+
+func (r *TerminalRepository) queryTerminals(ctx context.Context, q string, args ...any) (terminals []domain.Terminal, err error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	terminals = make([]domain.Terminal, 0)
+	for rows.Next() {
+		var t domain.Terminal
+		if err := rows.Scan(&t.ID, &t.Code, &t.Title, &t.Address, &t.LogoURL, &t.FreeTimePolicy, &t.Timezone, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, rows.Err()
+}
+
+func (r *TerminalRepository) queryAliases(ctx context.Context, q string, fn func(terminalID int64, alias string), args ...any) (err error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	for rows.Next() {
+		var terminalID int64
+		var alias string
+		if err := rows.Scan(&terminalID, &alias); err != nil {
+			return err
+		}
+		fn(terminalID, alias)
+	}
+	return rows.Err()
+}
+
+// Insert stores the terminal and its aliases in one transaction.
+func (r *TerminalRepository) Insert(ctx context.Context, t domain.Terminal) (id int64, err error) {
+	const q = `
+INSERT INTO terminals (code, title, address, logo_url, free_time_policy, timezone, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, q, t.Code, t.Title, t.Address, t.LogoURL, freeTimePolicyOrCalendar(t.FreeTimePolicy), t.Timezone,
+		t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return 0, conflictOr(err)
+	}
+	if id, err = res.LastInsertId(); err != nil {
+		return 0, err
+	}
+	if err = insertAliases(ctx, tx, id, t.Aliases); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update replaces the terminal fields and its aliases in one transaction.
+func (r *TerminalRepository) Update(ctx context.Context, t domain.Terminal) (err error) {
+	const lockQ = `SELECT id FROM terminals WHERE id = ? FOR UPDATE`
+	const updateQ = `
+UPDATE terminals
+SET code = ?, title = ?, address = ?, logo_url = ?, free_time_policy = ?, timezone = ?, updated_at = ?
+WHERE id = ?`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, lockQ, t.ID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, updateQ, t.Code, t.Title, t.Address, t.LogoURL, freeTimePolicyOrCalendar(t.FreeTimePolicy),
+		t.Timezone, t.UpdatedAt, t.ID); err != nil {
+		return conflictOr(err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM terminal_aliases WHERE terminal_id = ?", t.ID); err != nil {
+		return err
+	}
+	if err = insertAliases(ctx, tx, t.ID, t.Aliases); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertAliases(ctx context.Context, db execer, terminalID int64, aliases []string) error {
+	for _, alias := range aliases {
+		if _, err := db.ExecContext(ctx, "INSERT INTO terminal_aliases (terminal_id, alias) VALUES (?, ?)", terminalID, alias); err != nil {
+			return conflictOr(err)
+		}
+	}
+	return nil
+}
+
+// Delete removes the terminal; its aliases go with it. Records keep the title they were issued with.
+func (r *TerminalRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM terminals WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
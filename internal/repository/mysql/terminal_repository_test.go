@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTerminalListAttachesAliases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewTerminalRepository(db)
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, code, title").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "code", "title", "address", "logo_url", "free_time_policy", "timezone", "created_at", "updated_at"}).
+			AddRow(int64(1), "PABLB", "TERMINAL PACIFICO - BALBOA", "", "", "calendar_days", "", at, at).
+			AddRow(int64(2), "PAROD", "PANAMA PORTS COMPANY (RODMAN)", "Rodman, Panama", "", "business_days", "America/Panama", at, at))
+	mock.ExpectQuery("FROM terminal_aliases").WillReturnRows(
+		sqlmock.NewRows([]string{"terminal_id", "alias"}).
+			AddRow(int64(1), "BALBOA").
+			AddRow(int64(2), "PSA RODMAN").
+			AddRow(int64(2), "RODMAN"))
+	mock.ExpectClose()
+
+	terminals, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(terminals) != 2 || len(terminals[0].Aliases) != 1 || len(terminals[1].Aliases) != 2 ||
+		terminals[1].FreeTimePolicy != domain.FreeTimeBusinessDays {
+		t.Fatalf("unexpected terminals: %+v", terminals)
+	}
+}
+
+func TestTerminalInsertDuplicateAliasRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewTerminalRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO terminals").WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO terminal_aliases").WithArgs(int64(4), "RODMAN").
+		WillReturnError(&mysql.MySQLError{Number: 1062})
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	_, err = repo.Insert(context.Background(), domain.Terminal{Code: "PAROD", Title: "RODMAN", Aliases: []string{"RODMAN"}})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestTerminalUpdateUnknownIsNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil {
+			t.Errorf("failed to close db: %v", cerr)
+		}
+	}()
+
+	repo := NewTerminalRepository(db)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM terminals WHERE id = \\? FOR UPDATE").WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	if err := repo.Update(context.Background(), domain.Terminal{ID: 9, Code: "PAXXX"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
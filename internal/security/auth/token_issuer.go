@@ -32,13 +32,23 @@ func NewTokenIssuer(alg, issuer, audience string, ttl time.Duration, hsSecret st
 	}, nil
 }
 
-func (i *TokenIssuer) Issue(subject string) (string, time.Time, error) {
+// DefaultScope is granted to every issued token.
+const DefaultScope = "records:write"
+
+func (i *TokenIssuer) Issue(subject string, extraScopes ...string) (string, time.Time, error) {
 	now := time.Now().UTC()
 	expiresAt := now.Add(i.ttl)
 
+	scopes := []string{DefaultScope}
+	for _, scope := range extraScopes {
+		if scope != "" && scope != DefaultScope {
+			scopes = append(scopes, scope)
+		}
+	}
+
 	claims := Claims{
 		Subject: subject,
-		Scopes:  []string{"records:write"},
+		Scopes:  scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    i.issuer,
 			Subject:   subject,
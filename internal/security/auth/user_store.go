@@ -6,7 +6,8 @@ import (
 )
 
 type UserStore struct {
-	users map[string]string
+	users  map[string]string
+	scopes map[string][]string
 }
 
 func NewUserStore(users map[string]string) *UserStore {
@@ -24,3 +25,17 @@ func (s *UserStore) Validate(username, password string) bool {
 	}
 	return subtle.ConstantTimeCompare([]byte(expected), []byte(password)) == 1
 }
+
+// WithScopes grants additional token scopes per user on top of the default ones.
+func (s *UserStore) WithScopes(scopes map[string][]string) *UserStore {
+	cloned := make(map[string][]string, len(scopes))
+	for user, granted := range scopes {
+		cloned[strings.TrimSpace(user)] = append([]string(nil), granted...)
+	}
+	s.scopes = cloned
+	return s
+}
+
+func (s *UserStore) Scopes(username string) []string {
+	return s.scopes[strings.TrimSpace(username)]
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/unlocode"
+	"github.com/example/validacion-pases/pkg/problem"
+)
+
+const (
+	defaultPortSearchLimit = 20
+	maxPortSearchLimit     = 100
+)
+
+// CatalogHandler serves the reference data used to fill record forms.
+type CatalogHandler struct {
+	ports *unlocode.Directory
+}
+
+func NewCatalogHandler() *CatalogHandler {
+	return &CatalogHandler{}
+}
+
+// WithPorts enables the UN/LOCODE port endpoints.
+func (h *CatalogHandler) WithPorts(ports *unlocode.Directory) *CatalogHandler {
+	h.ports = ports
+	return h
+}
+
+type containerTypeListResponse struct {
+	ContainerTypes []containerTypeDTO `json:"container_types"`
+}
+
+type containerTypeDTO struct {
+	Code        string `json:"code"`
+	Length      string `json:"length"`
+	Height      string `json:"height"`
+	Group       string `json:"group"`
+	Description string `json:"description"`
+}
+
+// ContainerTypes lists the ISO size/type codes accepted as codigo_iso.
+func (h *CatalogHandler) ContainerTypes(w http.ResponseWriter, _ *http.Request) {
+	types := domain.ContainerTypes()
+	resp := containerTypeListResponse{ContainerTypes: make([]containerTypeDTO, 0, len(types))}
+	for _, ct := range types {
+		resp.ContainerTypes = append(resp.ContainerTypes, containerTypeDTO(ct))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type portListResponse struct {
+	Ports []portDTO `json:"ports"`
+}
+
+type portDTO struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Country     string `json:"country"`
+	CountryName string `json:"country_name"`
+	DisplayName string `json:"display_name"`
+}
+
+// Ports autocompletes puerto_descargue: q matches the start of a code or of any word of the
+// name, country narrows to one ISO 3166 country and limit caps the results (default 20, max 100).
+func (h *CatalogHandler) Ports(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := defaultPortSearchLimit
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxPortSearchLimit {
+			problem.Write(w, r, problem.BadRequest("limit must be between 1 and 100"))
+			return
+		}
+	}
+
+	resp := portListResponse{Ports: make([]portDTO, 0)}
+	if h.ports != nil {
+		for _, p := range h.ports.Search(q.Get("q"), q.Get("country"), limit) {
+			resp.Ports = append(resp.Ports, toPortDTO(p))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Port looks a port up by its UN/LOCODE.
+func (h *CatalogHandler) Port(w http.ResponseWriter, r *http.Request) {
+	if h.ports == nil {
+		problem.Write(w, r, problem.NotFound("port not found"))
+		return
+	}
+	p, ok := h.ports.Lookup(chi.URLParam(r, "code"))
+	if !ok {
+		problem.Write(w, r, problem.NotFound("port not found"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(toPortDTO(p))
+}
+
+func toPortDTO(p domain.Port) portDTO {
+	return portDTO{
+		Code:        p.Code,
+		Name:        p.Name,
+		Country:     p.Country,
+		CountryName: p.CountryName,
+		DisplayName: p.DisplayName(),
+	}
+}
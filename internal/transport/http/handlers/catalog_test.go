@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/example/validacion-pases/internal/platform/unlocode"
+)
+
+func TestContainerTypesHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewCatalogHandler().ContainerTypes(w, httptest.NewRequest(http.MethodGet, "/v1/catalog/container-types", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp containerTypeListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, ct := range resp.ContainerTypes {
+		if ct.Code == "45G1" {
+			found = ct.Description == "40' HIGH CUBE" && ct.Length == "40'"
+		}
+	}
+	if !found {
+		t.Fatalf("expected 45G1 as 40' HIGH CUBE in %+v", resp.ContainerTypes)
+	}
+}
+
+func TestPortsHandler(t *testing.T) {
+	ports, err := unlocode.Load("PA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewCatalogHandler().WithPorts(ports)
+
+	w := httptest.NewRecorder()
+	h.Ports(w, httptest.NewRequest(http.MethodGet, "/v1/catalog/ports?q=crist&country=pa", nil))
+	var resp portListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(resp.Ports) != 1 || resp.Ports[0].Code != "PACTB" || resp.Ports[0].DisplayName != "Cristóbal, Panamá" {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.Ports(w, httptest.NewRequest(http.MethodGet, "/v1/catalog/ports?limit=500", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for limit, got %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/catalog/ports/XXZZZ", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("code", "XXZZZ")
+	w = httptest.NewRecorder()
+	h.Port(w, r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
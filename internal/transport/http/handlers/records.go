@@ -1,26 +1,50 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/pdf"
+	"github.com/example/validacion-pases/internal/platform/qrcode"
 	"github.com/example/validacion-pases/internal/transport/http/middleware"
 	"github.com/example/validacion-pases/internal/usecase"
 	"github.com/example/validacion-pases/pkg/problem"
 )
 
 type RecordHandler struct {
-	service  *usecase.RecordService
-	validate *validator.Validate
+	service       *usecase.RecordService
+	validate      *validator.Validate
+	publicBaseURL string
+	// tariffOverrideScope lets its holders issue passes above the client's maximum free days.
+	tariffOverrideScope string
+	// exportWriteTimeout is the write deadline granted to each chunk of an export download.
+	exportWriteTimeout time.Duration
+	// importTimeout is the read and write deadline granted to an import request.
+	importTimeout time.Duration
 }
 
+const (
+	defaultQRImageSize = 256
+	minQRImageSize     = 64
+	maxQRImageSize     = 2048
+	passQRImageSize    = 512
+)
+
 type createRecordRequest struct {
 	Nave                string `json:"nave" validate:"required,max=150"`
 	Viaje               string `json:"viaje" validate:"required,max=100"`
@@ -38,44 +62,304 @@ type createRecordRequest struct {
 	Emision             string `json:"emision" validate:"omitempty,max=50"`
 	TituloTerminal      string `json:"titulo_terminal" validate:"omitempty,max=200"`
 	UsuarioFirma        string `json:"usuario_firma" validate:"omitempty,max=200"`
+	MaxUses             *int   `json:"max_uses" validate:"omitempty,gte=1,lte=1000"`
+	// Contenedores lists the containers of a pass with several boxes; it replaces
+	// contenedor_serie/codigo_iso, which describe a single container.
+	Contenedores []containerLineRequest `json:"contenedores" validate:"omitempty,max=4,dive"`
+}
+
+type containerLineRequest struct {
+	ContenedorSerie string `json:"contenedor_serie" validate:"max=100"`
+	CodigoISO       string `json:"codigo_iso" validate:"max=20"`
+	Cantidad        *int   `json:"cantidad" validate:"omitempty,gte=1,lte=4"`
 }
 
 type createRecordResponse struct {
 	ID                  int64  `json:"id"`
 	Emision             string `json:"emision"`
 	Contenedor          string `json:"contenedor"`
+	FechaReal           string `json:"fecha_real"`
 	LibreRetencionHasta string `json:"libre_retencion_hasta"`
 	TituloTerminal      string `json:"titulo_terminal"`
 	UsuarioFirma        string `json:"usuario_firma"`
+	PuertoLocode        string `json:"puerto_locode,omitempty"`
+	PuertoNombre        string `json:"puerto_nombre,omitempty"`
+	FreeTimePolicy      string `json:"free_time_policy"`
+	// Warnings flags accepted records that need review, e.g. a port missing from the terminal catalogue.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type validateRecordResponse struct {
-	Valid  bool             `json:"valid"`
-	Record recordPayloadDTO `json:"record"`
+	Valid      bool            `json:"valid"`
+	Result     string          `json:"result"`
+	Status     string          `json:"status"`
+	Revocation *revocationDTO  `json:"revocation,omitempty"`
+	Record     publicRecordDTO `json:"record"`
+}
+
+type revocationDTO struct {
+	RecordID  int64  `json:"record_id"`
+	Reason    string `json:"reason"`
+	RevokedBy string `json:"revoked_by"`
+	RevokedAt string `json:"revoked_at"`
+}
+
+type scanListResponse struct {
+	RecordID int64     `json:"record_id"`
+	Scans    []scanDTO `json:"scans"`
+}
+
+type scanDTO struct {
+	ID        int64  `json:"id"`
+	Outcome   string `json:"outcome"`
+	Scanner   string `json:"scanner"`
+	ClientIP  string `json:"client_ip"`
+	ScannedAt string `json:"scanned_at"`
+}
+
+type amendRecordRequest struct {
+	Nave            *string `json:"nave" validate:"omitempty,max=150"`
+	Viaje           *string `json:"viaje" validate:"omitempty,max=100"`
+	Cliente         *string `json:"cliente" validate:"omitempty,max=200"`
+	Booking         *string `json:"booking" validate:"omitempty,max=100"`
+	Rama            *string `json:"rama" validate:"omitempty,oneof=internacional nacional"`
+	ContenedorSerie *string `json:"contenedor_serie" validate:"omitempty,max=100"`
+	CodigoISO       *string `json:"codigo_iso" validate:"omitempty,max=20"`
+	Transportista   *string `json:"transportista" validate:"omitempty,max=200"`
+	PuertoDescargue *string `json:"puerto_descargue" validate:"omitempty,max=150"`
+	FechaReal       *string `json:"fecha_real" validate:"omitempty,datetime=2006-01-02"`
+	DiasLibre       *int    `json:"dias_libre" validate:"omitempty,gte=0,lte=365"`
+	// Contenedores replaces every container line of the pass.
+	Contenedores *[]containerLineRequest `json:"contenedores" validate:"omitempty,min=1,max=4,dive"`
+}
+
+type versionListResponse struct {
+	RecordID int64        `json:"record_id"`
+	Versions []versionDTO `json:"versions"`
+}
+
+type versionDTO struct {
+	Version   int              `json:"version"`
+	ChangedBy string           `json:"changed_by"`
+	ChangedAt string           `json:"changed_at"`
+	Changes   []fieldChangeDTO `json:"changes"`
+}
+
+type fieldChangeDTO struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type extendRecordRequest struct {
+	Days       int    `json:"days" validate:"required,gte=1,lte=365"`
+	Reason     string `json:"reason" validate:"required,max=500"`
+	ApprovedBy string `json:"approved_by" validate:"required,max=100"`
+}
+
+type extendRecordResponse struct {
+	Extension extensionDTO     `json:"extension"`
+	Record    recordPayloadDTO `json:"record"`
+}
+
+type extensionListResponse struct {
+	RecordID   int64          `json:"record_id"`
+	Extensions []extensionDTO `json:"extensions"`
+}
+
+type extensionDTO struct {
+	ID            int64  `json:"id"`
+	RecordID      int64  `json:"record_id"`
+	Days          int    `json:"days"`
+	Reason        string `json:"reason"`
+	ApprovedBy    string `json:"approved_by"`
+	RequestedBy   string `json:"requested_by"`
+	PreviousUntil string `json:"previous_until"`
+	NewUntil      string `json:"new_until"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type consumeRecordRequest struct {
+	Token string `json:"token" validate:"required,max=512"`
+}
+
+type revokeRecordRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// publicRecordDTO is the record as shown to whoever presents a QR token. It
+// deliberately omits the internal id, the signer, the version and the use
+// counter; those are only served by the authenticated GET /v1/records/{id}.
+type publicRecordDTO struct {
+	Emision               string             `json:"emision"`
+	Nave                  string             `json:"nave"`
+	Viaje                 string             `json:"viaje"`
+	Cliente               string             `json:"cliente"`
+	Booking               string             `json:"booking"`
+	Rama                  string             `json:"rama"`
+	Contenedor            string             `json:"contenedor"`
+	ContenedorDescripcion string             `json:"contenedor_descripcion"`
+	Contenedores          []containerLineDTO `json:"contenedores,omitempty"`
+	PuertoDescargue       string             `json:"puerto_descargue"`
+	PuertoLocode          string             `json:"puerto_locode"`
+	PuertoNombre          string             `json:"puerto_nombre"`
+	FechaReal             string             `json:"fecha_real"`
+	LibreRetencionHasta   string             `json:"libre_retencion_hasta"`
+	DiasLibre             int                `json:"dias_libre"`
+	FreeTimePolicy        string             `json:"free_time_policy"`
+	Transportista         string             `json:"transportista"`
+	TituloTerminal        string             `json:"titulo_terminal"`
+	Status                string             `json:"status"`
+	StatusUpdatedAt       string             `json:"status_updated_at,omitempty"`
+	MaxUses               *int               `json:"max_uses"`
+	CreatedAt             string             `json:"created_at"`
 }
 
+// recordPayloadDTO is the full record served to authenticated callers: the public view plus
+// the internal id, the signer, the use counter and the version.
 type recordPayloadDTO struct {
-	ID                  int64  `json:"id"`
-	Emision             string `json:"emision"`
-	Nave                string `json:"nave"`
-	Viaje               string `json:"viaje"`
-	Cliente             string `json:"cliente"`
-	Booking             string `json:"booking"`
-	Rama                string `json:"rama"`
-	Contenedor          string `json:"contenedor"`
-	PuertoDescargue     string `json:"puerto_descargue"`
-	LibreRetencionHasta string `json:"libre_retencion_hasta"`
-	DiasLibre           int    `json:"dias_libre"`
-	Transportista       string `json:"transportista"`
-	TituloTerminal      string `json:"titulo_terminal"`
-	UsuarioFirma        string `json:"usuario_firma"`
-	CreatedAt           string `json:"created_at"`
+	ID int64 `json:"id"`
+	publicRecordDTO
+	UsuarioFirma string `json:"usuario_firma"`
+	UseCount     int    `json:"use_count"`
+	Version      int    `json:"version"`
+}
+
+type containerLineDTO struct {
+	ContenedorSerie string `json:"contenedor_serie,omitempty"`
+	CodigoISO       string `json:"codigo_iso,omitempty"`
+	Cantidad        int    `json:"cantidad"`
+}
+
+type recordListResponse struct {
+	Records    []recordPayloadDTO `json:"records"`
+	NextCursor *int64             `json:"next_cursor"`
+}
+
+type issueQRTokenRequest struct {
+	TTLSeconds int64 `json:"ttl_seconds" validate:"omitempty,gt=0"`
+}
+
+type issueQRTokenResponse struct {
+	RecordID      int64  `json:"record_id"`
+	Token         string `json:"token"`
+	ExpiresAt     string `json:"expires_at"`
+	ValidationURL string `json:"validation_url"`
+}
+
+// toInput converts a validated create payload into the service input, accepting the legacy
+// contenedor and libre_retencion_hasta fields.
+func (req createRecordRequest) toInput(usuarioFirma string) (domain.CreateRecordInput, error) {
+	if strings.TrimSpace(req.ContenedorSerie) == "" {
+		req.ContenedorSerie = strings.TrimSpace(req.Contenedor)
+	}
+	if len(req.Contenedores) > 0 && (req.ContenedorSerie != "" || strings.TrimSpace(req.CodigoISO) != "") {
+		return domain.CreateRecordInput{}, errors.New("send either contenedores or contenedor_serie/codigo_iso, not both")
+	}
+
+	var fechaReal, libreRetencionHasta time.Time
+	var err error
+	switch {
+	case strings.TrimSpace(req.FechaReal) != "":
+		fechaReal, err = time.Parse("2006-01-02", req.FechaReal)
+		if err != nil {
+			return domain.CreateRecordInput{}, errors.New("fecha_real must use YYYY-MM-DD")
+		}
+	case strings.TrimSpace(req.LibreRetencionHasta) != "":
+		libreRetencionHasta, err = time.Parse("2006-01-02", req.LibreRetencionHasta)
+		if err != nil {
+			return domain.CreateRecordInput{}, errors.New("libre_retencion_hasta must use YYYY-MM-DD")
+		}
+	default:
+		return domain.CreateRecordInput{}, errors.New("fecha_real is required")
+	}
+
+	return domain.CreateRecordInput{
+		Nave:                req.Nave,
+		Viaje:               req.Viaje,
+		Cliente:             req.Cliente,
+		Booking:             req.Booking,
+		Rama:                req.Rama,
+		ContenedorSerie:     req.ContenedorSerie,
+		CodigoISO:           req.CodigoISO,
+		Containers:          toContainerLines(req.Contenedores),
+		FechaReal:           fechaReal,
+		LibreRetencionHasta: libreRetencionHasta,
+		DiasLibre:           req.DiasLibre,
+		Transportista:       req.Transportista,
+		PuertoDescargue:     req.PuertoDescargue,
+		UsuarioFirma:        usuarioFirma,
+		MaxUses:             req.MaxUses,
+	}, nil
+}
+
+// invalidRecordProblem describes a rejected record payload. Container numbers failing ISO 6346
+// are reported with the faulty part and, for a wrong check digit, the expected one; unknown
+// size/type codes and ports point at their catalogue.
+func invalidRecordProblem(err error, fallback string) problem.Details {
+	if errors.Is(err, domain.ErrUnknownTerminal) {
+		return problem.BadRequest("puerto_descargue is not in the terminal catalogue").WithInvalidParams(problem.InvalidParam{
+			Name:   "puerto_descargue",
+			Reason: "not in GET /v1/terminals",
+		})
+	}
+	var limitErr *domain.TariffLimitError
+	if errors.As(err, &limitErr) {
+		return problem.BadRequest(limitErr.Error()).WithInvalidParams(problem.InvalidParam{
+			Name:     "dias_libre",
+			Reason:   "exceeds the maximum of the client's tariff",
+			Expected: strconv.Itoa(limitErr.Max),
+		})
+	}
+	if errors.Is(err, domain.ErrUnknownContainerType) {
+		return problem.BadRequest("codigo_iso is not a known ISO size/type code").WithInvalidParams(problem.InvalidParam{
+			Name:   "codigo_iso",
+			Reason: "not in GET /v1/catalog/container-types",
+		})
+	}
+	var cnErr *domain.ContainerNumberError
+	if !errors.As(err, &cnErr) {
+		return problem.BadRequest(fallback)
+	}
+	param := problem.InvalidParam{Name: "contenedor_serie", Reason: cnErr.Reason, Part: string(cnErr.Part)}
+	if cnErr.ExpectedCheckDigit >= 0 {
+		param.Expected = strconv.Itoa(cnErr.ExpectedCheckDigit)
+	}
+	return problem.BadRequest("contenedor_serie is not a valid ISO 6346 container number: " + cnErr.Reason).WithInvalidParams(param)
+}
+
+func toContainerLines(reqs []containerLineRequest) []domain.ContainerLine {
+	if len(reqs) == 0 {
+		return nil
+	}
+	lines := make([]domain.ContainerLine, 0, len(reqs))
+	for _, req := range reqs {
+		line := domain.ContainerLine{Serie: req.ContenedorSerie, CodigoISO: req.CodigoISO}
+		if req.Cantidad != nil {
+			line.Cantidad = *req.Cantidad
+		}
+		lines = append(lines, line)
+	}
+	return lines
 }
 
 func NewRecordHandler(service *usecase.RecordService) *RecordHandler {
 	return &RecordHandler{service: service, validate: validator.New()}
 }
 
+// WithTariffOverrideScope sets the scope that allows dias_libre above the client's tariff on
+// create and import. Without it the tariff maximum applies to everyone.
+func (h *RecordHandler) WithTariffOverrideScope(scope string) *RecordHandler {
+	h.tariffOverrideScope = scope
+	return h
+}
+
+// WithPublicBaseURL sets the absolute base URL used when building validation URLs for QR codes.
+func (h *RecordHandler) WithPublicBaseURL(baseURL string) *RecordHandler {
+	h.publicBaseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return h
+}
+
 func (h *RecordHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req createRecordRequest
 	dec := json.NewDecoder(r.Body)
@@ -98,58 +382,24 @@ func (h *RecordHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.ContenedorSerie) == "" {
-		req.ContenedorSerie = strings.TrimSpace(req.Contenedor)
-	}
-
 	claims, err := middleware.ClaimsFromContext(r.Context())
 	if err != nil {
 		problem.Write(w, r, problem.Unauthorized("auth claims missing"))
 		return
 	}
 
-	var fechaReal time.Time
-	switch {
-	case strings.TrimSpace(req.FechaReal) != "":
-		fechaReal, err = time.Parse("2006-01-02", req.FechaReal)
-		if err != nil {
-			problem.Write(w, r, problem.BadRequest("fecha_real must use YYYY-MM-DD"))
-			return
-		}
-	case strings.TrimSpace(req.LibreRetencionHasta) != "":
-		lrh, parseErr := time.Parse("2006-01-02", req.LibreRetencionHasta)
-		if parseErr != nil {
-			problem.Write(w, r, problem.BadRequest("libre_retencion_hasta must use YYYY-MM-DD"))
-			return
-		}
-		diasLibre := 0
-		if req.DiasLibre != nil {
-			diasLibre = *req.DiasLibre
-		}
-		fechaReal = lrh.AddDate(0, 0, -diasLibre)
-	default:
-		problem.Write(w, r, problem.BadRequest("fecha_real is required"))
+	in, err := req.toInput(claims.Subject)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest(err.Error()))
 		return
 	}
+	in.OverrideTariff = h.tariffOverrideScope != "" && claims.HasScope(h.tariffOverrideScope)
 
-	id, rec, err := h.service.Create(r.Context(), domain.CreateRecordInput{
-		Nave:            req.Nave,
-		Viaje:           req.Viaje,
-		Cliente:         req.Cliente,
-		Booking:         req.Booking,
-		Rama:            req.Rama,
-		ContenedorSerie: req.ContenedorSerie,
-		CodigoISO:       req.CodigoISO,
-		FechaReal:       fechaReal,
-		DiasLibre:       req.DiasLibre,
-		Transportista:   req.Transportista,
-		PuertoDescargue: req.PuertoDescargue,
-		UsuarioFirma:    claims.Subject,
-	})
+	id, rec, err := h.service.Create(r.Context(), in)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidInput):
-			problem.Write(w, r, problem.BadRequest("invalid input"))
+			problem.Write(w, r, invalidRecordProblem(err, "invalid input"))
 		case errors.Is(err, domain.ErrConflict):
 			problem.Write(w, r, problem.Conflict("record already exists"))
 		case errors.Is(err, domain.ErrUnauthorized):
@@ -160,16 +410,28 @@ func (h *RecordHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(createRecordResponse{
+	resp := createRecordResponse{
 		ID:                  id,
-		Emision:             rec.Emision.Format(time.RFC3339),
+		Emision:             rec.Emision.In(h.service.Location(r.Context(), rec)).Format(time.RFC3339),
 		Contenedor:          rec.Contenedor,
+		FechaReal:           rec.BaseFechaReal().Format("2006-01-02"),
 		LibreRetencionHasta: rec.LibreRetencionHasta.Format("2006-01-02"),
 		TituloTerminal:      rec.TituloTerminal,
 		UsuarioFirma:        rec.UsuarioFirma,
-	})
+		PuertoLocode:        rec.PuertoLocode,
+		PuertoNombre:        rec.PuertoNombre,
+		FreeTimePolicy:      string(rec.FreeTimePolicy),
+	}
+	if rec.UnknownPort {
+		resp.Warnings = append(resp.Warnings, "puerto_descargue did not match a UN/LOCODE port; see GET /v1/catalog/ports")
+	}
+	if rec.UnknownTerminal {
+		resp.Warnings = append(resp.Warnings, "puerto_descargue is not in the terminal catalogue; titulo_terminal was derived from it")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (h *RecordHandler) Validate(w http.ResponseWriter, r *http.Request) {
@@ -179,7 +441,7 @@ func (h *RecordHandler) Validate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rec, err := h.service.FindByQRToken(r.Context(), token)
+	result, err := h.service.ValidateScan(r.Context(), token, scannerIdentity(r), clientIP(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, usecase.ErrInvalidQRToken), errors.Is(err, usecase.ErrExpiredQRToken):
@@ -196,22 +458,862 @@ func (h *RecordHandler) Validate(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(validateRecordResponse{
-		Valid: true,
-		Record: recordPayloadDTO{
-			Emision:             rec.Emision.UTC().Format(time.RFC3339),
-			Nave:                rec.Nave,
-			Viaje:               rec.Viaje,
-			Cliente:             rec.Cliente,
-			Booking:             rec.Booking,
-			Rama:                rec.Rama,
-			Contenedor:          rec.Contenedor,
-			PuertoDescargue:     rec.PuertoDescargue,
-			LibreRetencionHasta: rec.LibreRetencionHasta.Format("2006-01-02"),
-			DiasLibre:           rec.DiasLibre,
-			Transportista:       rec.Transportista,
-			TituloTerminal:      rec.TituloTerminal,
-			CreatedAt:           rec.CreatedAt.UTC().Format(time.RFC3339),
-		},
-	})
+	_ = json.NewEncoder(w).Encode(toValidateRecordResponse(result, h.service.Location(r.Context(), result.Record)))
+}
+
+func (h *RecordHandler) Consume(w http.ResponseWriter, r *http.Request) {
+	var req consumeRecordRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			problem.Write(w, r, problem.BadRequest("empty body"))
+			return
+		}
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return
+	}
+
+	result, err := h.service.Consume(r.Context(), strings.TrimSpace(req.Token), scannerIdentity(r), clientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPassNotConsumable):
+			problem.Write(w, r, problem.Conflict(fmt.Sprintf("pass cannot be consumed: status %s, uses %d of %s",
+				result.Status, result.Record.UseCount, formatMaxUses(result.Record.MaxUses))))
+		case errors.Is(err, usecase.ErrInvalidQRToken), errors.Is(err, usecase.ErrExpiredQRToken):
+			problem.Write(w, r, problem.BadRequest("invalid or expired qr token"))
+		case errors.Is(err, usecase.ErrQRVerifierUnavailable):
+			problem.Write(w, r, problem.ServiceUnavailable("qr verifier not configured"))
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to consume pass"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(toValidateRecordResponse(result, h.service.Location(r.Context(), result.Record)))
+}
+
+func toValidateRecordResponse(result usecase.QRValidation, loc *time.Location) validateRecordResponse {
+	resp := validateRecordResponse{
+		Valid:  result.Valid(),
+		Result: string(result.Status),
+		Status: string(result.Status),
+		Record: toPublicRecordDTO(result.Record, loc),
+	}
+	if resp.Valid {
+		resp.Result = "valid"
+	}
+	if result.Revocation != nil {
+		dto := toRevocationDTO(*result.Revocation)
+		resp.Revocation = &dto
+	}
+	return resp
+}
+
+func maxUsesPtr(maxUses int) *int {
+	if maxUses <= 0 {
+		return nil
+	}
+	return &maxUses
+}
+
+func formatMaxUses(maxUses int) string {
+	if maxUses <= 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(maxUses)
+}
+
+func (h *RecordHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	var req revokeRecordRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			problem.Write(w, r, problem.BadRequest("empty body"))
+			return
+		}
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return
+	}
+
+	claims, err := middleware.ClaimsFromContext(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Unauthorized("auth claims missing"))
+		return
+	}
+
+	rev, err := h.service.Revoke(r.Context(), recordID, req.Reason, claims.Subject)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			problem.Write(w, r, problem.BadRequest("invalid input"))
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		case errors.Is(err, domain.ErrConflict):
+			problem.Write(w, r, problem.Conflict("record is already revoked or can no longer be revoked"))
+		case errors.Is(err, domain.ErrUnauthorized):
+			problem.Write(w, r, problem.Unauthorized("unauthorized"))
+		case errors.Is(err, usecase.ErrRevocationsUnavailable):
+			problem.Write(w, r, problem.ServiceUnavailable("revocations not configured"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to revoke record"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toRevocationDTO(rev))
+}
+
+func (h *RecordHandler) Extend(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	var req extendRecordRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			problem.Write(w, r, problem.BadRequest("empty body"))
+			return
+		}
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return
+	}
+
+	claims, err := middleware.ClaimsFromContext(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Unauthorized("auth claims missing"))
+		return
+	}
+
+	ext, rec, err := h.service.Extend(r.Context(), recordID, req.Days, req.Reason, req.ApprovedBy, claims.Subject)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			problem.Write(w, r, problem.BadRequest("invalid input"))
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		case errors.Is(err, domain.ErrConflict):
+			problem.Write(w, r, problem.Conflict("only issued passes can be extended"))
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			problem.Write(w, r, problem.Conflict("record was modified concurrently; retry"))
+		case errors.Is(err, domain.ErrUnauthorized):
+			problem.Write(w, r, problem.Unauthorized("unauthorized"))
+		case errors.Is(err, usecase.ErrExtensionsUnavailable):
+			problem.Write(w, r, problem.ServiceUnavailable("extensions not configured"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to extend record"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(extendRecordResponse{Extension: toExtensionDTO(ext), Record: toRecordDTO(rec, h.service.Location(r.Context(), rec))})
+}
+
+func (h *RecordHandler) ListExtensions(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	exts, err := h.service.ListExtensions(r.Context(), recordID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		case errors.Is(err, usecase.ErrExtensionsUnavailable):
+			problem.Write(w, r, problem.ServiceUnavailable("extensions not configured"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to list extensions"))
+		}
+		return
+	}
+
+	resp := extensionListResponse{RecordID: recordID, Extensions: make([]extensionDTO, 0, len(exts))}
+	for _, ext := range exts {
+		resp.Extensions = append(resp.Extensions, toExtensionDTO(ext))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func toExtensionDTO(ext domain.Extension) extensionDTO {
+	return extensionDTO{
+		ID:            ext.ID,
+		RecordID:      ext.RecordID,
+		Days:          ext.Days,
+		Reason:        ext.Reason,
+		ApprovedBy:    ext.ApprovedBy,
+		RequestedBy:   ext.RequestedBy,
+		PreviousUntil: ext.PreviousUntil.Format("2006-01-02"),
+		NewUntil:      ext.NewUntil.Format("2006-01-02"),
+		CreatedAt:     ext.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *RecordHandler) ListScans(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+	limit := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			problem.Write(w, r, problem.BadRequest("limit must be a positive integer"))
+			return
+		}
+	}
+
+	scans, err := h.service.ListScans(r.Context(), recordID, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			problem.Write(w, r, problem.BadRequest("limit must not exceed 500"))
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		case errors.Is(err, usecase.ErrScanLogUnavailable):
+			problem.Write(w, r, problem.ServiceUnavailable("scan log not configured"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to list scans"))
+		}
+		return
+	}
+
+	resp := scanListResponse{RecordID: recordID, Scans: make([]scanDTO, 0, len(scans))}
+	for _, scan := range scans {
+		resp.Scans = append(resp.Scans, scanDTO{
+			ID:        scan.ID,
+			Outcome:   string(scan.Outcome),
+			Scanner:   scan.Scanner,
+			ClientIP:  scan.ClientIP,
+			ScannedAt: scan.ScannedAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *RecordHandler) Get(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	rec, err := h.service.GetRecord(r.Context(), recordID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			problem.Write(w, r, problem.NotFound("record not found"))
+			return
+		}
+		problem.Write(w, r, problem.Internal("failed to load record"))
+		return
+	}
+
+	body, etag, err := h.encodeRecord(r, rec)
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to encode record"))
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+func (h *RecordHandler) Amend(w http.ResponseWriter, r *http.Request) {
+	claims, err := middleware.ClaimsFromContext(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Unauthorized("missing auth claims"))
+		return
+	}
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		problem.Write(w, r, problem.PreconditionRequired("If-Match header with the record version is required"))
+		return
+	}
+	expectedVersion, etag, err := parseIfMatch(ifMatch)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest("If-Match must be the record ETag or its version number"))
+		return
+	}
+
+	var req amendRecordRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			problem.Write(w, r, problem.BadRequest("empty body"))
+			return
+		}
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return
+	}
+	if req.Contenedores != nil && (req.ContenedorSerie != nil || req.CodigoISO != nil) {
+		problem.Write(w, r, problem.BadRequest("send either contenedores or contenedor_serie/codigo_iso, not both"))
+		return
+	}
+
+	in := domain.AmendRecordInput{
+		Nave:            req.Nave,
+		Viaje:           req.Viaje,
+		Cliente:         req.Cliente,
+		Booking:         req.Booking,
+		Rama:            req.Rama,
+		ContenedorSerie: req.ContenedorSerie,
+		CodigoISO:       req.CodigoISO,
+		Transportista:   req.Transportista,
+		PuertoDescargue: req.PuertoDescargue,
+		DiasLibre:       req.DiasLibre,
+		ChangedBy:       claims.Subject,
+	}
+	if req.Contenedores != nil {
+		lines := toContainerLines(*req.Contenedores)
+		in.Containers = &lines
+	}
+	if req.FechaReal != nil {
+		fechaReal, err := time.Parse("2006-01-02", *req.FechaReal)
+		if err != nil {
+			problem.Write(w, r, problem.BadRequest("fecha_real must use format YYYY-MM-DD"))
+			return
+		}
+		in.FechaReal = &fechaReal
+	}
+
+	if etag != "" {
+		current, err := h.service.GetRecord(r.Context(), recordID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				problem.Write(w, r, problem.NotFound("record not found"))
+				return
+			}
+			problem.Write(w, r, problem.Internal("failed to load record"))
+			return
+		}
+		_, currentETag, err := h.encodeRecord(r, current)
+		if err != nil {
+			problem.Write(w, r, problem.Internal("failed to encode record"))
+			return
+		}
+		if currentETag != etag {
+			problem.Write(w, r, problem.PreconditionFailed("record was modified; fetch it again and retry with the current ETag"))
+			return
+		}
+	}
+
+	rec, err := h.service.Amend(r.Context(), recordID, expectedVersion, in)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			problem.Write(w, r, problem.PreconditionFailed("record was modified; fetch it again and retry with the current version"))
+		case errors.Is(err, domain.ErrConflict):
+			problem.Write(w, r, problem.Conflict("amendment conflicts with another record or the pass status"))
+		case errors.Is(err, domain.ErrInvalidInput):
+			problem.Write(w, r, invalidRecordProblem(err, "invalid business rules for rama/contenedor data"))
+		case errors.Is(err, domain.ErrUnauthorized):
+			problem.Write(w, r, problem.Unauthorized("missing subject"))
+		case errors.Is(err, domain.ErrNotFound):
+			problem.Write(w, r, problem.NotFound("record not found"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to amend record"))
+		}
+		return
+	}
+
+	body, newETag, err := h.encodeRecord(r, rec)
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to encode record"))
+		return
+	}
+	w.Header().Set("ETag", newETag)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+func (h *RecordHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	versions, err := h.service.ListVersions(r.Context(), recordID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			problem.Write(w, r, problem.NotFound("record not found"))
+			return
+		}
+		problem.Write(w, r, problem.Internal("failed to list record versions"))
+		return
+	}
+
+	resp := versionListResponse{RecordID: recordID, Versions: make([]versionDTO, 0, len(versions))}
+	for _, v := range versions {
+		dto := versionDTO{
+			Version:   v.Version,
+			ChangedBy: v.ChangedBy,
+			ChangedAt: v.ChangedAt.UTC().Format(time.RFC3339),
+			Changes:   make([]fieldChangeDTO, 0, len(v.Changes)),
+		}
+		for _, c := range v.Changes {
+			dto.Changes = append(dto.Changes, fieldChangeDTO(c))
+		}
+		resp.Versions = append(resp.Versions, dto)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// encodeRecord renders the authenticated record representation and its entity tag.
+func (h *RecordHandler) encodeRecord(r *http.Request, rec domain.Record) ([]byte, string, error) {
+	body, err := json.Marshal(toRecordDTO(rec, h.service.Location(r.Context(), rec)))
+	if err != nil {
+		return nil, "", err
+	}
+	return body, recordETag(rec.Version, body), nil
+}
+
+// recordETag derives a strong entity tag from the encoded representation. The version prefix
+// lets PATCH recover the optimistic-lock version from an echoed ETag.
+func recordETag(version int, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.Itoa(version) + "-" + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// parseIfMatch accepts either a record ETag, returned as etag with the version it carries, or a
+// plain version number (quoted or not). Weak tags are rejected because If-Match uses the strong
+// comparison (RFC 9110 13.1.1).
+func parseIfMatch(raw string) (version int, etag string, err error) {
+	if strings.HasPrefix(raw, "W/") {
+		return 0, "", errors.New("weak entity tag")
+	}
+	value := raw
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	prefix, _, tagged := strings.Cut(value, "-")
+	version, err = strconv.Atoi(prefix)
+	if err != nil || version <= 0 {
+		return 0, "", errors.New("invalid version")
+	}
+	if tagged {
+		if value == raw {
+			return 0, "", errors.New("unquoted entity tag")
+		}
+		return version, raw, nil
+	}
+	return version, "", nil
+}
+
+// etagMatches implements the weak comparison used for If-None-Match (RFC 9110 13.1.2).
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *RecordHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.RecordFilter{
+		Booking:         strings.TrimSpace(q.Get("booking")),
+		Viaje:           strings.TrimSpace(q.Get("viaje")),
+		Nave:            strings.TrimSpace(q.Get("nave")),
+		Cliente:         strings.TrimSpace(q.Get("cliente")),
+		Contenedor:      strings.ToUpper(strings.TrimSpace(q.Get("contenedor"))),
+		Rama:            strings.ToLower(strings.TrimSpace(q.Get("rama"))),
+		PuertoDescargue: strings.TrimSpace(q.Get("puerto_descargue")),
+		PuertoLocode:    strings.ToUpper(strings.TrimSpace(q.Get("puerto_locode"))),
+		UsuarioFirma:    strings.TrimSpace(q.Get("usuario_firma")),
+	}
+
+	var err error
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+		end  bool
+	}{
+		{"emision_from", &filter.EmisionFrom, false},
+		{"emision_to", &filter.EmisionTo, true},
+		{"created_from", &filter.CreatedFrom, false},
+		{"created_to", &filter.CreatedTo, true},
+	} {
+		if *p.dst, err = parseTimeBound(q.Get(p.name), p.end, h.service.DefaultLocation()); err != nil {
+			problem.Write(w, r, problem.BadRequest(p.name+" must be a date (YYYY-MM-DD) or RFC3339 timestamp"))
+			return
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		filter.Limit, err = strconv.Atoi(raw)
+		if err != nil || filter.Limit <= 0 {
+			problem.Write(w, r, problem.BadRequest("limit must be a positive integer"))
+			return
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("cursor")); raw != "" {
+		filter.AfterID, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || filter.AfterID <= 0 {
+			problem.Write(w, r, problem.BadRequest("cursor must be a value returned as next_cursor"))
+			return
+		}
+	}
+
+	page, err := h.service.SearchRecords(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			problem.Write(w, r, problem.BadRequest("invalid search filter"))
+			return
+		}
+		problem.Write(w, r, problem.Internal("failed to search records"))
+		return
+	}
+
+	resp := recordListResponse{Records: make([]recordPayloadDTO, 0, len(page.Records))}
+	for _, rec := range page.Records {
+		resp.Records = append(resp.Records, toRecordDTO(rec, h.service.Location(r.Context(), rec)))
+	}
+	if page.NextCursor > 0 {
+		resp.NextCursor = &page.NextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// parseTimeBound accepts an RFC3339 timestamp or a YYYY-MM-DD date, which starts at midnight in
+// loc (the operating timezone). A date used as an exclusive upper bound covers the whole day.
+func parseTimeBound(raw string, end bool, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day.UTC(), nil
+}
+
+// toRecordDTO formats the instants of rec with the offset of loc, the operating timezone of its
+// terminal. fecha_real and libre_retencion_hasta are calendar dates and are printed as stored.
+func toRecordDTO(rec domain.Record, loc *time.Location) recordPayloadDTO {
+	return recordPayloadDTO{
+		ID:              rec.ID,
+		publicRecordDTO: toPublicRecordDTO(rec, loc),
+		UsuarioFirma:    rec.UsuarioFirma,
+		UseCount:        rec.UseCount,
+		Version:         rec.Version,
+	}
+}
+
+// toPublicRecordDTO builds the fields of rec that may be shown to anyone holding its QR token,
+// with the same formatting as toRecordDTO.
+func toPublicRecordDTO(rec domain.Record, loc *time.Location) publicRecordDTO {
+	dto := publicRecordDTO{
+		Emision:               rec.Emision.In(loc).Format(time.RFC3339),
+		Nave:                  rec.Nave,
+		Viaje:                 rec.Viaje,
+		Cliente:               rec.Cliente,
+		Booking:               rec.Booking,
+		Rama:                  rec.Rama,
+		Contenedor:            rec.Contenedor,
+		ContenedorDescripcion: usecase.ContainerDescription(rec),
+		PuertoDescargue:       rec.PuertoDescargue,
+		PuertoLocode:          rec.PuertoLocode,
+		PuertoNombre:          rec.PuertoNombre,
+		FechaReal:             rec.BaseFechaReal().Format("2006-01-02"),
+		LibreRetencionHasta:   rec.LibreRetencionHasta.Format("2006-01-02"),
+		DiasLibre:             rec.DiasLibre,
+		FreeTimePolicy:        string(rec.FreeTimePolicy),
+		Transportista:         rec.Transportista,
+		TituloTerminal:        rec.TituloTerminal,
+		Status:                string(rec.Status),
+		MaxUses:               maxUsesPtr(rec.MaxUses),
+		CreatedAt:             rec.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+	for _, line := range rec.Containers {
+		dto.Contenedores = append(dto.Contenedores, containerLineDTO{
+			ContenedorSerie: line.Serie,
+			CodigoISO:       line.CodigoISO,
+			Cantidad:        line.Cantidad,
+		})
+	}
+	if !rec.StatusUpdatedAt.IsZero() {
+		dto.StatusUpdatedAt = rec.StatusUpdatedAt.In(loc).Format(time.RFC3339)
+	}
+	return dto
+}
+
+// scannerIdentity prefers the authenticated subject and falls back to the X-Scanner-ID header
+// sent by anonymous gate devices.
+func scannerIdentity(r *http.Request) string {
+	if claims, err := middleware.ClaimsFromContext(r.Context()); err == nil {
+		return claims.Subject
+	}
+	return strings.TrimSpace(r.Header.Get("X-Scanner-ID"))
+}
+
+// clientIP returns the caller address as resolved by chimiddleware.RealIP.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func toRevocationDTO(rev domain.Revocation) revocationDTO {
+	return revocationDTO{
+		RecordID:  rev.RecordID,
+		Reason:    rev.Reason,
+		RevokedBy: rev.RevokedBy,
+		RevokedAt: rev.RevokedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *RecordHandler) IssueQRToken(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	var req issueQRTokenRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return
+	}
+
+	token, expiresAt, err := h.service.IssueQRToken(r.Context(), recordID, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		writeQRIssueError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(issueQRTokenResponse{
+		RecordID:      recordID,
+		Token:         token,
+		ExpiresAt:     expiresAt.UTC().Format(time.RFC3339),
+		ValidationURL: h.validationURL(token),
+	})
+}
+
+func (h *RecordHandler) QRCodePNG(w http.ResponseWriter, r *http.Request) {
+	h.writeQRCode(w, r, "image/png", qrcode.PNG)
+}
+
+func (h *RecordHandler) QRCodeSVG(w http.ResponseWriter, r *http.Request) {
+	h.writeQRCode(w, r, "image/svg+xml", qrcode.SVG)
+}
+
+func (h *RecordHandler) writeQRCode(w http.ResponseWriter, r *http.Request, contentType string, render func(string, int, qrcode.Level) ([]byte, error)) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	size := defaultQRImageSize
+	if raw := strings.TrimSpace(r.URL.Query().Get("size")); raw != "" {
+		size, err = strconv.Atoi(raw)
+		if err != nil || size < minQRImageSize || size > maxQRImageSize {
+			problem.Write(w, r, problem.BadRequest("size must be an integer between 64 and 2048"))
+			return
+		}
+	}
+	level, err := qrcode.ParseLevel(r.URL.Query().Get("ecc"))
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest("ecc must be one of L, M, Q, H"))
+		return
+	}
+
+	token, _, err := h.service.IssueQRToken(r.Context(), recordID, 0)
+	if err != nil {
+		writeQRIssueError(w, r, err)
+		return
+	}
+
+	img, err := render(h.validationURL(token), size, level)
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to render qr code"))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(img)
+}
+
+func (h *RecordHandler) PassPDF(w http.ResponseWriter, r *http.Request) {
+	recordID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || recordID <= 0 {
+		problem.Write(w, r, problem.BadRequest("record id must be a positive integer"))
+		return
+	}
+
+	rec, token, _, err := h.service.FindWithQRToken(r.Context(), recordID, 0)
+	if err != nil {
+		writeQRIssueError(w, r, err)
+		return
+	}
+
+	validationURL := h.validationURL(token)
+	qrPNG, err := qrcode.PNG(validationURL, passQRImageSize, qrcode.LevelQuartile)
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to render qr code"))
+		return
+	}
+
+	// The address is printed only while the catalogue still carries the title the pass was issued with.
+	var address string
+	if t, ok, err := h.service.LookupTerminal(r.Context(), rec); err == nil && ok && t.Title == rec.TituloTerminal {
+		address = t.Address
+	}
+
+	puerto := rec.PuertoDescargue
+	if rec.PuertoLocode != "" {
+		puerto = rec.PuertoNombre + " (" + rec.PuertoLocode + ")"
+	}
+
+	var buf bytes.Buffer
+	err = pdf.RenderPass(&buf, pdf.Pass{
+		RecordID:            rec.ID,
+		TituloTerminal:      rec.TituloTerminal,
+		TerminalAddress:     address,
+		Emision:             rec.Emision.In(h.service.Location(r.Context(), rec)).Format("2006-01-02 15:04:05"),
+		Nave:                rec.Nave,
+		Viaje:               rec.Viaje,
+		Cliente:             rec.Cliente,
+		Booking:             rec.Booking,
+		Contenedor:          usecase.ContainerDescription(rec),
+		PuertoDescargue:     puerto,
+		LibreRetencionHasta: rec.LibreRetencionHasta.Format("2006-01-02"),
+		DiasLibre:           rec.DiasLibre,
+		DiasHabiles:         rec.FreeTimePolicy == domain.FreeTimeBusinessDays,
+		Transportista:       rec.Transportista,
+		UsuarioFirma:        rec.UsuarioFirma,
+		QRCodePNG:           qrPNG,
+		ValidationURL:       validationURL,
+		GeneratedAt:         time.Now().UTC(),
+	})
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to render pass"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="pase-%d.pdf"`, rec.ID))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func writeQRIssueError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidQRTokenTTL):
+		problem.Write(w, r, problem.BadRequest("ttl_seconds is out of the allowed range"))
+	case errors.Is(err, usecase.ErrQRIssuerUnavailable):
+		problem.Write(w, r, problem.ServiceUnavailable("qr issuer not configured"))
+	case errors.Is(err, domain.ErrNotFound):
+		problem.Write(w, r, problem.NotFound("record not found"))
+	default:
+		problem.Write(w, r, problem.Internal("failed to issue qr token"))
+	}
+}
+
+func (h *RecordHandler) validationURL(token string) string {
+	return h.publicBaseURL + "/v1/records/validate?t=" + url.QueryEscape(token)
 }
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/sheet"
+	"github.com/example/validacion-pases/pkg/problem"
+)
+
+// exportColumns are the export headers, named after the business fields listed in the README.
+// Export converts EMISION to the operating timezone of the terminal of each pass beforehand.
+var exportColumns = []struct {
+	header string
+	value  func(rec domain.Record) string
+}{
+	{"ID", func(rec domain.Record) string { return strconv.FormatInt(rec.ID, 10) }},
+	{"EMISION", func(rec domain.Record) string { return rec.Emision.Format(time.RFC3339) }},
+	{"NAVE", func(rec domain.Record) string { return rec.Nave }},
+	{"VIAJE", func(rec domain.Record) string { return rec.Viaje }},
+	{"CLIENTE", func(rec domain.Record) string { return rec.Cliente }},
+	{"BOOKING", func(rec domain.Record) string { return rec.Booking }},
+	{"CONTENEDOR", func(rec domain.Record) string { return rec.Contenedor }},
+	{"FECHA_REAL", func(rec domain.Record) string { return rec.BaseFechaReal().Format("2006-01-02") }},
+	{"LIBRE_DE_RETENCION_HASTA", func(rec domain.Record) string { return rec.LibreRetencionHasta.Format("2006-01-02") }},
+	{"DIAS_LIBRE", func(rec domain.Record) string { return strconv.Itoa(rec.DiasLibre) }},
+	{"TRANSPORTISTA", func(rec domain.Record) string { return rec.Transportista }},
+	{"TITULO_TERMINAL", func(rec domain.Record) string { return rec.TituloTerminal }},
+	{"USUARIO_FIRMA", func(rec domain.Record) string { return rec.UsuarioFirma }},
+	{"STATUS", func(rec domain.Record) string { return string(rec.Status) }},
+}
+
+// WithExportWriteTimeout sets the write deadline given to each chunk of an export. Exports are
+// served outside the request timeout, so long downloads only fail when the client stops reading.
+func (h *RecordHandler) WithExportWriteTimeout(d time.Duration) *RecordHandler {
+	h.exportWriteTimeout = d
+	return h
+}
+
+// exportResponseWriter defers the download headers until the first byte of the file is written,
+// so that failures before that point can still be reported as a problem response. Every write
+// pushes the connection write deadline forward by writeTimeout.
+type exportResponseWriter struct {
+	http.ResponseWriter
+	format       sheet.Format
+	started      bool
+	writeTimeout time.Duration
+}
+
+func (w *exportResponseWriter) Write(p []byte) (int, error) {
+	if w.writeTimeout > 0 {
+		if err := http.NewResponseController(w.ResponseWriter).SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return 0, err
+		}
+	}
+	if !w.started {
+		w.started = true
+		filename := "records-" + time.Now().UTC().Format("20060102") + "." + string(w.format)
+		w.Header().Set("Content-Type", sheet.ContentType(w.format))
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Export streams the records matching booking, cliente, terminal (titulo_terminal) and the
+// emision range as a CSV (default) or XLSX download, oldest first.
+func (h *RecordHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := sheet.FormatCSV
+	if raw := strings.TrimSpace(q.Get("format")); raw != "" {
+		var err error
+		if format, err = sheet.ParseFormat(raw); err != nil {
+			problem.Write(w, r, problem.BadRequest("format must be csv or xlsx"))
+			return
+		}
+	}
+
+	filter := domain.RecordFilter{
+		Booking:        strings.TrimSpace(q.Get("booking")),
+		Cliente:        strings.TrimSpace(q.Get("cliente")),
+		TituloTerminal: strings.TrimSpace(q.Get("terminal")),
+	}
+	var err error
+	if filter.EmisionFrom, err = parseTimeBound(q.Get("emision_from"), false, h.service.DefaultLocation()); err != nil {
+		problem.Write(w, r, problem.BadRequest("emision_from must be a date (YYYY-MM-DD) or RFC3339 timestamp"))
+		return
+	}
+	if filter.EmisionTo, err = parseTimeBound(q.Get("emision_to"), true, h.service.DefaultLocation()); err != nil {
+		problem.Write(w, r, problem.BadRequest("emision_to must be a date (YYYY-MM-DD) or RFC3339 timestamp"))
+		return
+	}
+
+	out := &exportResponseWriter{ResponseWriter: w, format: format, writeTimeout: h.exportWriteTimeout}
+	sw, err := sheet.NewWriter(out, format)
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to export records"))
+		return
+	}
+
+	headers := make([]string, len(exportColumns))
+	for i, c := range exportColumns {
+		headers[i] = c.header
+	}
+	err = sw.WriteRow(headers)
+	if err == nil {
+		cells := make([]string, len(exportColumns))
+		err = h.service.ExportRecords(r.Context(), filter, func(rec domain.Record) error {
+			rec.Emision = rec.Emision.In(h.service.Location(r.Context(), rec))
+			for i, c := range exportColumns {
+				cells[i] = c.value(rec)
+			}
+			return sw.WriteRow(cells)
+		})
+	}
+	if err == nil {
+		err = sw.Close()
+	} else {
+		sw.Abort()
+	}
+	if err == nil {
+		return
+	}
+
+	if out.started {
+		// The status line is already sent; abort the connection so the client sees a truncated
+		// download instead of a file that looks complete.
+		panic(http.ErrAbortHandler)
+	}
+	if errors.Is(err, domain.ErrInvalidInput) {
+		problem.Write(w, r, problem.BadRequest("invalid export filter"))
+		return
+	}
+	problem.Write(w, r, problem.Internal("failed to export records"))
+}
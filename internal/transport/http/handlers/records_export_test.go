@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/sheet"
+	"github.com/example/validacion-pases/internal/usecase"
+)
+
+func TestExportRecordsHandlerCSV(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	r := httptest.NewRequest(http.MethodGet, "/v1/records/export?cliente=CAPITAL+PACIFICO%2C+S.A.&emision_from=2026-02-01&emision_to=2026-02-28", nil)
+	w := httptest.NewRecorder()
+
+	h.Export(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, ".csv") {
+		t.Fatalf("unexpected content disposition %q", cd)
+	}
+
+	rows, err := sheet.ReadAll(w.Body, sheet.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d", len(rows))
+	}
+	if strings.Join(rows[0][:4], ",") != "ID,EMISION,NAVE,VIAJE" {
+		t.Fatalf("unexpected header: %q", rows[0])
+	}
+	if rows[1][0] != "121" || rows[1][6] != "YMLU5374938" || rows[1][8] != "2026-03-06" {
+		t.Fatalf("unexpected first row: %q", rows[1])
+	}
+}
+
+func TestExportRecordsHandlerXLSX(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	r := httptest.NewRequest(http.MethodGet, "/v1/records/export?format=xlsx", nil)
+	w := httptest.NewRecorder()
+
+	h.Export(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	rows, err := sheet.ReadAll(bytes.NewReader(w.Body.Bytes()), sheet.FormatXLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][1] != "EMISION" {
+		t.Fatalf("unexpected rows: %q", rows)
+	}
+}
+
+func TestExportRecordsHandlerRejectsBadRequests(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	for _, target := range []string{
+		"/v1/records/export?format=pdf",
+		"/v1/records/export?emision_from=ayer",
+		"/v1/records/export?emision_from=2026-03-01&emision_to=2026-02-01",
+	} {
+		w := httptest.NewRecorder()
+		h.Export(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s: unexpected content type %q", target, ct)
+		}
+	}
+}
+
+// slowStreamRepo streams the test records with a pause before each one, like a large export.
+type slowStreamRepo struct {
+	testRepo
+	delay time.Duration
+}
+
+func (r slowStreamRepo) Stream(ctx context.Context, filter domain.RecordFilter, fn func(domain.Record) error) error {
+	return r.testRepo.Stream(ctx, filter, func(rec domain.Record) error {
+		time.Sleep(r.delay)
+		return fn(rec)
+	})
+}
+
+func TestExportRecordsHandlerOutlivesServerWriteTimeout(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(slowStreamRepo{delay: 100 * time.Millisecond})).
+		WithExportWriteTimeout(time.Second)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(h.Export))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/v1/records/export")
+	if err != nil {
+		t.Fatalf("export was cut off: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	rows, err := sheet.ReadAll(resp.Body, sheet.FormatCSV)
+	if err != nil {
+		t.Fatalf("export was cut off: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d", len(rows))
+	}
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/platform/sheet"
+	"github.com/example/validacion-pases/internal/transport/http/middleware"
+	"github.com/example/validacion-pases/internal/usecase"
+	"github.com/example/validacion-pases/pkg/problem"
+)
+
+const importMaxMemory = 8 << 20
+
+// importColumn binds a create payload field to the manifest headers recognised for it by
+// default: the field name and the business name used in the README.
+type importColumn struct {
+	field   string
+	aliases []string
+	set     func(req *createRecordRequest, value string) error
+}
+
+var importColumns = []importColumn{
+	{field: "nave", aliases: []string{"NAVE"}, set: func(req *createRecordRequest, v string) error { req.Nave = v; return nil }},
+	{field: "viaje", aliases: []string{"VIAJE"}, set: func(req *createRecordRequest, v string) error { req.Viaje = v; return nil }},
+	{field: "cliente", aliases: []string{"CLIENTE"}, set: func(req *createRecordRequest, v string) error { req.Cliente = v; return nil }},
+	{field: "booking", aliases: []string{"BOOKING"}, set: func(req *createRecordRequest, v string) error { req.Booking = v; return nil }},
+	{field: "rama", aliases: []string{"RAMA"}, set: func(req *createRecordRequest, v string) error { req.Rama = strings.ToLower(v); return nil }},
+	{field: "contenedor_serie", aliases: []string{"CONTENEDOR_SERIE", "CONTENEDOR"}, set: func(req *createRecordRequest, v string) error { req.ContenedorSerie = v; return nil }},
+	{field: "codigo_iso", aliases: []string{"CODIGO_ISO"}, set: func(req *createRecordRequest, v string) error { req.CodigoISO = v; return nil }},
+	{field: "fecha_real", aliases: []string{"FECHA_REAL"}, set: func(req *createRecordRequest, v string) error { req.FechaReal = v; return nil }},
+	{field: "libre_retencion_hasta", aliases: []string{"LIBRE_RETENCION_HASTA", "LIBRE_DE_RETENCION_HASTA"}, set: func(req *createRecordRequest, v string) error { req.LibreRetencionHasta = v; return nil }},
+	{field: "dias_libre", aliases: []string{"DIAS_LIBRE"}, set: func(req *createRecordRequest, v string) error { return setImportInt(&req.DiasLibre, "dias_libre", v) }},
+	{field: "transportista", aliases: []string{"TRANSPORTISTA"}, set: func(req *createRecordRequest, v string) error { req.Transportista = v; return nil }},
+	{field: "puerto_descargue", aliases: []string{"PUERTO_DESCARGUE"}, set: func(req *createRecordRequest, v string) error { req.PuertoDescargue = v; return nil }},
+	{field: "max_uses", aliases: []string{"MAX_USES"}, set: func(req *createRecordRequest, v string) error { return setImportInt(&req.MaxUses, "max_uses", v) }},
+}
+
+type importRecordsResponse struct {
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Conflicts int               `json:"conflicts"`
+	Invalid   int               `json:"invalid"`
+	Rows      []importResultDTO `json:"rows"`
+}
+
+type importResultDTO struct {
+	Line     int    `json:"line"`
+	Outcome  string `json:"outcome"`
+	RecordID int64  `json:"record_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// WithImportTimeout sets the read and write deadline of an import request, replacing the server
+// timeouts that are sized for regular API calls.
+func (h *RecordHandler) WithImportTimeout(d time.Duration) *RecordHandler {
+	h.importTimeout = d
+	return h
+}
+
+// Import creates records from an uploaded CSV or XLSX manifest. The multipart form carries the
+// file in "file", an optional "format" (csv or xlsx, inferred from the file name otherwise) and
+// an optional "mapping" JSON object from payload field to manifest column header.
+func (h *RecordHandler) Import(w http.ResponseWriter, r *http.Request) {
+	claims, err := middleware.ClaimsFromContext(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Unauthorized("auth claims missing"))
+		return
+	}
+	if h.importTimeout > 0 {
+		rc := http.NewResponseController(w)
+		deadline := time.Now().Add(h.importTimeout)
+		if err := errors.Join(rc.SetReadDeadline(deadline), rc.SetWriteDeadline(deadline)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			problem.Write(w, r, problem.Internal("failed to prepare import"))
+			return
+		}
+	}
+
+	if err := r.ParseMultipartForm(importMaxMemory); err != nil {
+		problem.Write(w, r, problem.BadRequest("invalid multipart form"))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest("file is required"))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	var format sheet.Format
+	if raw := r.FormValue("format"); raw != "" {
+		format, err = sheet.ParseFormat(raw)
+	} else {
+		format, err = sheet.FormatFromFilename(header.Filename)
+	}
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest("format must be csv or xlsx"))
+		return
+	}
+
+	var mapping map[string]string
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			problem.Write(w, r, problem.BadRequest("mapping must be a json object of field to column"))
+			return
+		}
+	}
+
+	table, err := sheet.ReadAll(file, format)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest("could not read "+string(format)+" file"))
+		return
+	}
+
+	rows, err := h.importRows(table, mapping, claims.Subject)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest(err.Error()))
+		return
+	}
+	if h.tariffOverrideScope != "" && claims.HasScope(h.tariffOverrideScope) {
+		for i := range rows {
+			rows[i].Input.OverrideTariff = true
+		}
+	}
+
+	results, err := h.service.ImportRecords(r.Context(), rows)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			problem.Write(w, r, problem.BadRequest("file must contain between 1 and 5000 data rows"))
+		case errors.Is(err, domain.ErrUnauthorized):
+			problem.Write(w, r, problem.Unauthorized("unauthorized"))
+		default:
+			problem.Write(w, r, problem.Internal("failed to import records"))
+		}
+		return
+	}
+
+	resp := importRecordsResponse{Total: len(results), Rows: make([]importResultDTO, 0, len(results))}
+	for _, res := range results {
+		switch res.Outcome {
+		case usecase.ImportCreated:
+			resp.Created++
+		case usecase.ImportConflict:
+			resp.Conflicts++
+		case usecase.ImportInvalid:
+			resp.Invalid++
+		}
+		resp.Rows = append(resp.Rows, importResultDTO{
+			Line:     res.Line,
+			Outcome:  string(res.Outcome),
+			RecordID: res.RecordID,
+			Error:    res.Error,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// importRows maps the data rows of table to service rows. The first non-blank row is the header;
+// blank rows are skipped and lines are 1-based positions in the file.
+func (h *RecordHandler) importRows(table [][]string, mapping map[string]string, usuarioFirma string) ([]usecase.ImportRow, error) {
+	headerIdx := -1
+	for i, row := range table {
+		if !blankRow(row) {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil, errors.New("file has no header row")
+	}
+
+	positions, err := importColumnPositions(table[headerIdx], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]usecase.ImportRow, 0, len(table)-headerIdx-1)
+	for i := headerIdx + 1; i < len(table); i++ {
+		if blankRow(table[i]) {
+			continue
+		}
+		row := usecase.ImportRow{Line: i + 1}
+		row.Input, row.Err = h.importInput(table[i], positions, usuarioFirma)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (h *RecordHandler) importInput(cells []string, positions []int, usuarioFirma string) (domain.CreateRecordInput, error) {
+	var req createRecordRequest
+	for col, pos := range positions {
+		if pos < 0 || pos >= len(cells) {
+			continue
+		}
+		value := strings.TrimSpace(cells[pos])
+		if value == "" {
+			continue
+		}
+		if err := importColumns[col].set(&req, value); err != nil {
+			return domain.CreateRecordInput{}, err
+		}
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return domain.CreateRecordInput{}, validationMessage(err)
+	}
+	return req.toInput(usuarioFirma)
+}
+
+// importColumnPositions resolves each import column to its position in header, using mapping
+// when given and the default aliases otherwise; unmatched columns get -1.
+func importColumnPositions(header []string, mapping map[string]string) ([]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, name := range header {
+		key := normalizeHeader(name)
+		if _, dup := byName[key]; !dup && key != "" {
+			byName[key] = i
+		}
+	}
+
+	known := make(map[string]int, len(importColumns))
+	for col, c := range importColumns {
+		known[c.field] = col
+	}
+	for field := range mapping {
+		if _, ok := known[field]; !ok {
+			return nil, fmt.Errorf("mapping references unknown field %q", field)
+		}
+	}
+
+	positions := make([]int, len(importColumns))
+	for col, c := range importColumns {
+		positions[col] = -1
+		if column, ok := mapping[c.field]; ok {
+			pos, found := byName[normalizeHeader(column)]
+			if !found {
+				return nil, fmt.Errorf("column %q mapped to %s not found in header", column, c.field)
+			}
+			positions[col] = pos
+			continue
+		}
+		for _, alias := range c.aliases {
+			if pos, found := byName[alias]; found {
+				positions[col] = pos
+				break
+			}
+		}
+	}
+	return positions, nil
+}
+
+func normalizeHeader(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func setImportInt(dst **int, field, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer", field)
+	}
+	*dst = &n
+	return nil
+}
+
+// validationMessage names the first payload field rejected by the validator using its json name.
+func validationMessage(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) == 0 {
+		return errors.New("payload validation failed")
+	}
+	fe := verrs[0]
+	name := fe.Field()
+	if f, ok := reflect.TypeOf(createRecordRequest{}).FieldByName(fe.StructField()); ok {
+		name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+	}
+	if fe.Tag() == "required" {
+		return fmt.Errorf("%s is required", name)
+	}
+	return fmt.Errorf("%s is invalid", name)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/security/auth"
+	"github.com/example/validacion-pases/internal/transport/http/middleware"
+	"github.com/example/validacion-pases/internal/usecase"
+)
+
+func newImportRequest(t *testing.T, filename, content string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/records:import", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+}
+
+func TestImportRecordsHandlerReportsRows(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	csv := "NAVE,VIAJE,CLIENTE,BOOKING,CONTENEDOR,FECHA_REAL,DIAS_LIBRE,PUERTO_DESCARGUE\n" +
+		"NYK DENEB,072E,CAPITAL PACIFICO,BK1,YMLU5374938,2026-02-17,17,BALBOA\n" +
+		"\n" +
+		"NYK DENEB,072E,CAPITAL PACIFICO,DUPLICADO,YMLU5374938,2026-02-17,17,BALBOA\n" +
+		"NYK DENEB,072E,CAPITAL PACIFICO,BK3,YMLU5374938,17/02/2026,17,BALBOA\n" +
+		"NYK DENEB,072E,,BK4,YMLU5374938,2026-02-17,17,BALBOA\n"
+
+	w := httptest.NewRecorder()
+	h.Import(w, newImportRequest(t, "manifest.csv", csv, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp importRecordsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 4 || resp.Created != 1 || resp.Conflicts != 1 || resp.Invalid != 2 {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+	want := []importResultDTO{
+		{Line: 2, Outcome: "created", RecordID: 200},
+		{Line: 4, Outcome: "conflict", Error: "record already exists"},
+		{Line: 5, Outcome: "invalid", Error: "fecha_real is invalid"},
+		{Line: 6, Outcome: "invalid", Error: "cliente is required"},
+	}
+	for i, row := range want {
+		if resp.Rows[i] != row {
+			t.Fatalf("row %d: expected %+v, got %+v", i, row, resp.Rows[i])
+		}
+	}
+}
+
+func TestImportRecordsHandlerAppliesMapping(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	csv := "Vessel;Voyage;Consignee;Booking No;Container;Discharge date;POD\n" +
+		"NYK DENEB;072E;CAPITAL PACIFICO;BK1;YMLU5374938;2026-02-17;BALBOA\n"
+	mapping := `{"nave":"Vessel","viaje":"Voyage","cliente":"Consignee","booking":"Booking No","contenedor_serie":"Container","fecha_real":"Discharge date","puerto_descargue":"POD"}`
+
+	w := httptest.NewRecorder()
+	h.Import(w, newImportRequest(t, "manifest.csv", csv, map[string]string{"mapping": mapping}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp importRecordsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Created != 1 {
+		t.Fatalf("expected one created row, got %+v", resp)
+	}
+}
+
+func TestImportRecordsHandlerRejectsUnknownMappedColumn(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	w := httptest.NewRecorder()
+	h.Import(w, newImportRequest(t, "manifest.csv", "NAVE\nNYK DENEB\n", map[string]string{"mapping": `{"nave":"Vessel"}`}))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestImportRecordsHandlerRejectsUnsupportedFormat(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	w := httptest.NewRecorder()
+	h.Import(w, newImportRequest(t, "manifest.pdf", "NAVE\n", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+// slowBatchRepo pauses before every batch insert, like a large manifest against MySQL.
+type slowBatchRepo struct {
+	testRepo
+	delay time.Duration
+}
+
+func (r slowBatchRepo) InsertBatch(ctx context.Context, records []domain.Record) ([]domain.BatchInsertResult, error) {
+	time.Sleep(r.delay)
+	return r.testRepo.InsertBatch(ctx, records)
+}
+
+func TestImportRecordsHandlerOutlivesServerTimeouts(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(slowBatchRepo{delay: 300 * time.Millisecond})).
+		WithImportTimeout(time.Second)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Import(w, r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"})))
+	}))
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	csv := "NAVE,VIAJE,CLIENTE,BOOKING,CONTENEDOR,FECHA_REAL,DIAS_LIBRE,PUERTO_DESCARGUE\n" +
+		"NYK DENEB,072E,CAPITAL PACIFICO,BK1,YMLU5374938,2026-02-17,17,BALBOA\n"
+	req := newImportRequest(t, "manifest.csv", csv, nil)
+	resp, err := srv.Client().Post(srv.URL+"/v1/records:import", req.Header.Get("Content-Type"), req.Body)
+	if err != nil {
+		t.Fatalf("import was cut off: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var out importRecordsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("import was cut off: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || out.Created != 1 {
+		t.Fatalf("unexpected response %d: %+v", resp.StatusCode, out)
+	}
+}
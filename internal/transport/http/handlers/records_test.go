@@ -6,12 +6,16 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/example/validacion-pases/internal/domain"
 	"github.com/example/validacion-pases/internal/security/auth"
 	"github.com/example/validacion-pases/internal/transport/http/middleware"
@@ -21,6 +25,56 @@ import (
 type testRepo struct{}
 
 func (testRepo) Insert(_ context.Context, _ domain.Record) (int64, error) { return 123, nil }
+func (testRepo) UpdateStatus(_ context.Context, _ int64, _, _ domain.RecordStatus, _ time.Time) error {
+	return nil
+}
+func (testRepo) ConsumeUse(_ context.Context, _ int64, _ time.Time) error { return nil }
+func (testRepo) Amend(_ context.Context, _ domain.Record, expectedVersion int, _ domain.RecordVersion) error {
+	if expectedVersion != 1 {
+		return domain.ErrPreconditionFailed
+	}
+	return nil
+}
+func (testRepo) ListVersions(_ context.Context, id int64) ([]domain.RecordVersion, error) {
+	return []domain.RecordVersion{{
+		RecordID:  id,
+		Version:   2,
+		ChangedBy: "user-1",
+		ChangedAt: time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC),
+		Changes:   []domain.FieldChange{{Field: "nave", Before: "NYK DENEP", After: "NYK DENEB"}},
+	}}, nil
+}
+func (r testRepo) Stream(ctx context.Context, _ domain.RecordFilter, fn func(domain.Record) error) error {
+	for id := int64(121); id <= 123; id++ {
+		rec, _ := r.FindByID(ctx, id)
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (testRepo) InsertBatch(_ context.Context, records []domain.Record) ([]domain.BatchInsertResult, error) {
+	results := make([]domain.BatchInsertResult, len(records))
+	for i, rec := range records {
+		if rec.Booking == "DUPLICADO" {
+			results[i].Err = domain.ErrConflict
+			continue
+		}
+		results[i].ID = int64(200 + i)
+	}
+	return results, nil
+}
+func (r testRepo) Search(ctx context.Context, filter domain.RecordFilter) ([]domain.Record, error) {
+	var out []domain.Record
+	for id := int64(130); id > 120 && len(out) < filter.Limit; id-- {
+		if filter.AfterID > 0 && id >= filter.AfterID {
+			continue
+		}
+		rec, _ := r.FindByID(ctx, id)
+		out = append(out, rec)
+	}
+	return out, nil
+}
 func (testRepo) FindByID(_ context.Context, id int64) (domain.Record, error) {
 	return domain.Record{
 		ID:                  id,
@@ -32,18 +86,21 @@ func (testRepo) FindByID(_ context.Context, id int64) (domain.Record, error) {
 		Rama:                "internacional",
 		Contenedor:          "YMLU5374938",
 		PuertoDescargue:     "RODMAN",
+		FechaReal:           time.Date(2026, 2, 17, 0, 0, 0, 0, time.UTC),
 		LibreRetencionHasta: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC),
 		DiasLibre:           17,
 		Transportista:       "",
 		TituloTerminal:      "PANAMA PORTS COMPANY (RODMAN)",
 		UsuarioFirma:        "Admin",
+		Status:              domain.StatusIssued,
+		Version:             1,
 		CreatedAt:           time.Date(2026, 2, 17, 9, 41, 45, 0, time.UTC),
 	}, nil
 }
 
 func TestCreateRecordHandler(t *testing.T) {
 	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
-	body := []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","rama":"internacional","contenedor_serie":"ABCU1234567","fecha_real":"2026-02-09","dias_libre":2,"puerto_descargue":"Balboa"}`)
+	body := []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","rama":"internacional","contenedor_serie":"ABCU1234560","fecha_real":"2026-02-09","dias_libre":2,"puerto_descargue":"Balboa"}`)
 	r := httptest.NewRequest(http.MethodPost, "/v1/records", bytes.NewReader(body))
 	r.Header.Set("Content-Type", "application/json")
 	claims := &auth.Claims{Subject: "user-1"}
@@ -71,6 +128,75 @@ func TestCreateRecordBadPayload(t *testing.T) {
 	}
 }
 
+func TestCreateRecordReportsWrongCheckDigit(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	body := []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","rama":"internacional","contenedor_serie":"MSCU 123456-7","fecha_real":"2026-02-09","puerto_descargue":"Balboa"}`)
+	r := httptest.NewRequest(http.MethodPost, "/v1/records", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+
+	w := httptest.NewRecorder()
+	h.Create(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	var resp struct {
+		InvalidParams []struct {
+			Name     string `json:"name"`
+			Part     string `json:"part"`
+			Expected string `json:"expected"`
+		} `json:"invalid_params"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.InvalidParams) != 1 || resp.InvalidParams[0].Name != "contenedor_serie" ||
+		resp.InvalidParams[0].Part != "check_digit" || resp.InvalidParams[0].Expected != "6" {
+		t.Fatalf("unexpected problem: %s", w.Body.String())
+	}
+}
+
+type testTariffs []domain.ClientTariff
+
+func (t testTariffs) List(_ context.Context) ([]domain.ClientTariff, error) { return t, nil }
+
+func TestCreateRecordEnforcesClientTariff(t *testing.T) {
+	tariffs := testTariffs{{Cliente: "CLIENTE 1", DefaultDiasLibre: 5, MaxDiasLibre: 10, ValidFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}).WithTariffs(usecase.NewClientTariffs(tariffs, 0))).
+		WithTariffOverrideScope("tariffs:override")
+	body := []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","rama":"internacional","contenedor_serie":"ABCU1234560","fecha_real":"2026-02-09","dias_libre":15,"puerto_descargue":"Balboa"}`)
+	create := func(scopes ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/records", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1", Scopes: scopes}))
+		w := httptest.NewRecorder()
+		h.Create(w, r)
+		return w
+	}
+
+	w := create("records:write")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		InvalidParams []struct {
+			Name     string `json:"name"`
+			Expected string `json:"expected"`
+		} `json:"invalid_params"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.InvalidParams) != 1 || resp.InvalidParams[0].Name != "dias_libre" || resp.InvalidParams[0].Expected != "10" {
+		t.Fatalf("unexpected problem: %s", w.Body.String())
+	}
+
+	if w := create("records:write", "tariffs:override"); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 with the override scope, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestCreateRecordAcceptsLegacyPayloadShape(t *testing.T) {
 	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
 	body := []byte(`{"emision":"2026-02-17 09:41:45","nave":"NYK DENEB","viaje":"072E","cliente":"CAPITAL PACIFICO, S.A.","booking":"YMLUL160382911","contenedor":"YMLU5374938","puerto_descargue":"RODMAN","libre_retencion_hasta":"2021-03-06","dias_libre":0,"transportista":"GLOBERUNNERS, INC","titulo_terminal":"PANAMA PORTS COMPANY (RODMAN)","usuario_firma":"Admin"}`)
@@ -86,6 +212,38 @@ func TestCreateRecordAcceptsLegacyPayloadShape(t *testing.T) {
 	}
 }
 
+func TestCreateRecordWithSeveralContainers(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}))
+	body := []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","contenedores":[{"codigo_iso":"22G1","cantidad":2},{"codigo_iso":"45G1"}],"transportista":"TRANSPORTE SA","fecha_real":"2026-02-09","puerto_descargue":"Cristobal"}`)
+	r := httptest.NewRequest(http.MethodPost, "/v1/records", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+
+	w := httptest.NewRecorder()
+	h.Create(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp createRecordResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Contenedor != "2 X 22G1 + 1 X 45G1" {
+		t.Fatalf("unexpected contenedor %q", resp.Contenedor)
+	}
+
+	body = []byte(`{"nave":"NAVE 1","viaje":"VJ1","cliente":"CLIENTE 1","booking":"BK1","contenedor_serie":"ABCU1234560","contenedores":[{"contenedor_serie":"YMLU5374938"}],"fecha_real":"2026-02-09","puerto_descargue":"Balboa"}`)
+	r = httptest.NewRequest(http.MethodPost, "/v1/records", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+	w = httptest.NewRecorder()
+	h.Create(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 when mixing both shapes, got %d", w.Code)
+	}
+}
+
 func TestValidateRecordHandler(t *testing.T) {
 	secret := "test-qr-secret"
 	verifier := usecase.NewCompactQRTokenVerifier(secret)
@@ -103,6 +261,215 @@ func TestValidateRecordHandler(t *testing.T) {
 	}
 }
 
+func TestValidateRecordHandlerOmitsInternalFields(t *testing.T) {
+	secret := "test-qr-secret"
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}, usecase.NewCompactQRTokenVerifier(secret)))
+
+	token := signedCompactToken(123, secret, time.Now().Add(10*time.Minute).Unix())
+	r := httptest.NewRequest(http.MethodGet, "/v1/records/validate?t="+token, nil)
+	w := httptest.NewRecorder()
+	h.Validate(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Record map[string]json.RawMessage `json:"record"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(resp.Record) == 0 {
+		t.Fatal("expected record payload in validate response")
+	}
+	for _, field := range []string{"id", "usuario_firma", "version", "use_count"} {
+		if _, ok := resp.Record[field]; ok {
+			t.Fatalf("validate response must not expose %q: %v", field, resp.Record)
+		}
+	}
+}
+
+type revokedRepo struct{}
+
+func (revokedRepo) Revoke(_ context.Context, _ domain.Revocation, _ domain.RecordStatus) error {
+	return domain.ErrConflict
+}
+func (revokedRepo) FindByRecordID(_ context.Context, id int64) (domain.Revocation, error) {
+	return domain.Revocation{
+		RecordID:  id,
+		Reason:    "contenedor incorrecto",
+		RevokedBy: "supervisor",
+		RevokedAt: time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func TestValidateRevokedRecordHandler(t *testing.T) {
+	secret := "test-qr-secret"
+	svc := usecase.NewRecordService(testRepo{}, usecase.NewCompactQRTokenVerifier(secret)).WithRevocations(revokedRepo{})
+	h := NewRecordHandler(svc)
+
+	token := signedCompactToken(123, secret, time.Now().Add(10*time.Minute).Unix())
+	r := httptest.NewRequest(http.MethodGet, "/v1/records/validate?t="+token, nil)
+	w := httptest.NewRecorder()
+	h.Validate(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp validateRecordResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Valid || resp.Result != "revoked" || resp.Revocation == nil || resp.Revocation.RevokedBy != "supervisor" {
+		t.Fatalf("unexpected validation response: %+v", resp)
+	}
+}
+
+func TestRevokeRecordAlreadyRevoked(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}).WithRevocations(revokedRepo{}))
+	r := httptest.NewRequest(http.MethodPost, "/v1/records/123/revoke", bytes.NewReader([]byte(`{"reason":"contenedor incorrecto"}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r = withURLParam(r, "id", "123")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "supervisor"}))
+
+	w := httptest.NewRecorder()
+	h.Revoke(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+type testScans struct {
+	inserted []domain.Scan
+}
+
+func (s *testScans) Insert(_ context.Context, scan domain.Scan) error {
+	s.inserted = append(s.inserted, scan)
+	return nil
+}
+
+func (s *testScans) ListByRecordID(_ context.Context, _ int64, _ int) ([]domain.Scan, error) {
+	return s.inserted, nil
+}
+
+func TestValidateRecordsScanAndListScans(t *testing.T) {
+	secret := "test-qr-secret"
+	scans := &testScans{}
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}, usecase.NewCompactQRTokenVerifier(secret)).WithScanLog(scans))
+
+	token := signedCompactToken(123, secret, time.Now().Add(10*time.Minute).Unix())
+	r := httptest.NewRequest(http.MethodGet, "/v1/records/validate?t="+token, nil)
+	r.RemoteAddr = "10.0.0.5:41234"
+	r.Header.Set("X-Scanner-ID", "gate-norte-1")
+	h.Validate(httptest.NewRecorder(), r)
+
+	lr := withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123/scans", nil), "id", "123")
+	w := httptest.NewRecorder()
+	h.ListScans(w, lr)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp scanListResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(resp.Scans) != 1 || resp.Scans[0].Scanner != "gate-norte-1" || resp.Scans[0].ClientIP != "10.0.0.5" {
+		t.Fatalf("unexpected scans: %+v", resp.Scans)
+	}
+}
+
+func TestIssueQRTokenHandler(t *testing.T) {
+	secret := "test-qr-secret"
+	svc := usecase.NewRecordService(testRepo{}, usecase.NewCompactQRTokenVerifier(secret)).
+		WithQRTokenIssuer(usecase.NewCompactQRTokenIssuer(secret, time.Hour, 24*time.Hour))
+	h := NewRecordHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/records/123/qr-token", bytes.NewReader([]byte(`{"ttl_seconds":600}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r = withURLParam(r, "id", "123")
+
+	w := httptest.NewRecorder()
+	h.IssueQRToken(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp issueQRTokenResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	vr := httptest.NewRequest(http.MethodGet, resp.ValidationURL, nil)
+	vw := httptest.NewRecorder()
+	h.Validate(vw, vr)
+	if vw.Code != http.StatusOK {
+		t.Fatalf("expected issued token to validate, got %d: %s", vw.Code, vw.Body.String())
+	}
+}
+
+func TestQRCodePNGHandler(t *testing.T) {
+	h := newQRRecordHandler()
+	r := withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123/qr.png?size=128&ecc=H", nil), "id", "123")
+
+	w := httptest.NewRecorder()
+	h.QRCodePNG(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("\x89PNG")) {
+		t.Fatal("response is not a png image")
+	}
+}
+
+func TestQRCodeSVGHandlerRejectsInvalidECC(t *testing.T) {
+	h := newQRRecordHandler()
+	r := withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123/qr.svg?ecc=X", nil), "id", "123")
+
+	w := httptest.NewRecorder()
+	h.QRCodeSVG(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestPassPDFHandler(t *testing.T) {
+	h := newQRRecordHandler()
+	r := withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123/pass.pdf", nil), "id", "123")
+
+	w := httptest.NewRecorder()
+	h.PassPDF(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatal("response is not a pdf document")
+	}
+}
+
+func newQRRecordHandler() *RecordHandler {
+	secret := "test-qr-secret"
+	svc := usecase.NewRecordService(testRepo{}, usecase.NewCompactQRTokenVerifier(secret)).
+		WithQRTokenIssuer(usecase.NewCompactQRTokenIssuer(secret, time.Hour, 24*time.Hour))
+	return NewRecordHandler(svc).WithPublicBaseURL("https://api.example.com")
+}
+
+func withURLParam(r *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
 func signedCompactToken(recordID int64, secret string, exp int64) string {
 	body := fmt.Sprintf("v1|%d|%d", recordID, exp)
 	mac := hmac.New(sha256.New, []byte(secret))
@@ -110,3 +477,268 @@ func signedCompactToken(recordID int64, secret string, exp int64) string {
 	sig := mac.Sum(nil)[:16]
 	return fmt.Sprintf("v1.%d.%d.%s", recordID, exp, base64.RawURLEncoding.EncodeToString(sig))
 }
+
+type consumableRepo struct{ testRepo }
+
+func (r consumableRepo) FindByID(ctx context.Context, id int64) (domain.Record, error) {
+	rec, err := r.testRepo.FindByID(ctx, id)
+	rec.LibreRetencionHasta = time.Now().UTC().AddDate(0, 0, 7)
+	rec.MaxUses = 2
+	return rec, err
+}
+
+func TestConsumeRecordHandler(t *testing.T) {
+	secret := "test-qr-secret"
+	h := NewRecordHandler(usecase.NewRecordService(consumableRepo{}, usecase.NewCompactQRTokenVerifier(secret)))
+
+	token := signedCompactToken(123, secret, time.Now().Add(10*time.Minute).Unix())
+	r := httptest.NewRequest(http.MethodPost, "/v1/records/consume", strings.NewReader(`{"token":"`+token+`"}`))
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+	w := httptest.NewRecorder()
+	h.Consume(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"valid":true`) {
+		t.Fatalf("expected valid response, got %s", w.Body.String())
+	}
+}
+
+func TestConsumeRecordHandlerRequiresToken(t *testing.T) {
+	h := newQRRecordHandler()
+	w := httptest.NewRecorder()
+	h.Consume(w, httptest.NewRequest(http.MethodPost, "/v1/records/consume", strings.NewReader(`{}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestListRecordsHandlerPaginates(t *testing.T) {
+	h := newQRRecordHandler()
+
+	w := httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/v1/records?booking=YMLUL160382911&limit=3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var page recordListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 3 || page.Records[0].ID != 130 || page.NextCursor == nil || *page.NextCursor != 128 {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	w = httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/v1/records?limit=3&cursor=128", nil))
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 3 || page.Records[0].ID != 127 {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+}
+
+func TestListRecordsHandlerRejectsBadDate(t *testing.T) {
+	h := newQRRecordHandler()
+	w := httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/v1/records?emision_from=09/02/2026", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestParseTimeBoundUsesOperatingTimezone(t *testing.T) {
+	panama, err := time.LoadLocation("America/Panama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		raw  string
+		end  bool
+		want time.Time
+	}{
+		{"2026-02-12", false, time.Date(2026, 2, 12, 5, 0, 0, 0, time.UTC)},
+		{"2026-02-12", true, time.Date(2026, 2, 13, 5, 0, 0, 0, time.UTC)},
+		{"2026-02-12T23:30:00Z", false, time.Date(2026, 2, 12, 23, 30, 0, 0, time.UTC)},
+	} {
+		got, err := parseTimeBound(tc.raw, tc.end, panama)
+		if err != nil || !got.Equal(tc.want) || got.Location() != time.UTC {
+			t.Errorf("%s (end=%v): expected %s, got %s (%v)", tc.raw, tc.end, tc.want, got, err)
+		}
+	}
+}
+
+func TestGetRecordHandlerFormatsInstantsInOperatingTimezone(t *testing.T) {
+	h := newQRRecordHandler()
+	w := httptest.NewRecorder()
+	h.Get(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123", nil), "id", "123"))
+	var rec recordPayloadDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	// Issued at 09:41:45 UTC, which is 04:41:45 in Panama; calendar dates are unchanged.
+	if rec.Emision != "2026-02-17T04:41:45-05:00" || rec.FechaReal != "2026-02-17" || rec.LibreRetencionHasta != "2026-03-06" {
+		t.Fatalf("unexpected dates: emision=%s fecha_real=%s libre_retencion_hasta=%s", rec.Emision, rec.FechaReal, rec.LibreRetencionHasta)
+	}
+}
+
+func TestGetRecordHandlerSupportsConditionalRequests(t *testing.T) {
+	h := newQRRecordHandler()
+
+	w := httptest.NewRecorder()
+	h.Get(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123", nil), "id", "123"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rec recordPayloadDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.ID != 123 || rec.UsuarioFirma != "Admin" || rec.Status != "issued" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	r := withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123", nil), "id", "123")
+	r.Header.Set("If-None-Match", `"stale", W/`+etag)
+	w = httptest.NewRecorder()
+	h.Get(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAmendRecordHandler(t *testing.T) {
+	h := newQRRecordHandler()
+	newRequest := func(ifMatch string) *http.Request {
+		r := httptest.NewRequest(http.MethodPatch, "/v1/records/123", strings.NewReader(`{"nave":"NYK DENEB II","dias_libre":20}`))
+		r = withURLParam(r, "id", "123")
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		return r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+	}
+
+	w := httptest.NewRecorder()
+	h.Amend(w, newRequest(""))
+	if w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("expected 428, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Amend(w, newRequest(`"2"`))
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Amend(w, newRequest(`"1"`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rec recordPayloadDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Version != 2 || rec.Nave != "NYK DENEB II" || rec.LibreRetencionHasta != "2026-03-09" {
+		t.Fatalf("unexpected amended record: %+v", rec)
+	}
+}
+
+func TestAmendRecordHandlerAcceptsETagFromGet(t *testing.T) {
+	h := newQRRecordHandler()
+	w := httptest.NewRecorder()
+	h.Get(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123", nil), "id", "123"))
+	etag := w.Header().Get("ETag")
+
+	newRequest := func(ifMatch string) *http.Request {
+		r := httptest.NewRequest(http.MethodPatch, "/v1/records/123", strings.NewReader(`{"nave":"NYK DENEB II"}`))
+		r = withURLParam(r, "id", "123")
+		r.Header.Set("If-Match", ifMatch)
+		return r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+	}
+
+	w = httptest.NewRecorder()
+	h.Amend(w, newRequest("W/"+etag))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a weak tag, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Amend(w, newRequest(`"1-00000000000000000000000000000000"`))
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale ETag, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Amend(w, newRequest(etag))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); !strings.HasPrefix(got, `"2-`) {
+		t.Fatalf("expected the ETag of version 2, got %q", got)
+	}
+}
+
+func TestListVersionsHandler(t *testing.T) {
+	h := newQRRecordHandler()
+	w := httptest.NewRecorder()
+	h.ListVersions(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/records/123/versions", nil), "id", "123"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"before":"NYK DENEP"`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+type testExtensions struct{}
+
+func (testExtensions) Extend(_ context.Context, _ domain.Record, _ int, _ domain.RecordVersion, _ domain.Extension) (int64, error) {
+	return 1, nil
+}
+func (testExtensions) ListByRecordID(_ context.Context, _ int64) ([]domain.Extension, error) {
+	return nil, nil
+}
+
+func TestExtendRecordHandler(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}).WithExtensions(testExtensions{}))
+
+	body := `{"days":3,"reason":"negociado con cliente","approved_by":"gerente-comercial"}`
+	r := withURLParam(httptest.NewRequest(http.MethodPost, "/v1/records/123/extensions", strings.NewReader(body)), "id", "123")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+	w := httptest.NewRecorder()
+	h.Extend(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp extendRecordResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Extension.NewUntil != "2026-03-09" || resp.Record.LibreRetencionHasta != "2026-03-09" || resp.Record.DiasLibre != 20 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestExtendRecordHandlerRequiresApprover(t *testing.T) {
+	h := NewRecordHandler(usecase.NewRecordService(testRepo{}).WithExtensions(testExtensions{}))
+
+	r := withURLParam(httptest.NewRequest(http.MethodPost, "/v1/records/123/extensions", strings.NewReader(`{"days":3,"reason":"x"}`)), "id", "123")
+	r = r.WithContext(middleware.WithClaims(r.Context(), &auth.Claims{Subject: "user-1"}))
+	w := httptest.NewRecorder()
+	h.Extend(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/usecase"
+	"github.com/example/validacion-pases/pkg/problem"
+)
+
+// TerminalHandler serves the terminal catalogue and its admin API.
+type TerminalHandler struct {
+	catalog  *usecase.TerminalCatalog
+	validate *validator.Validate
+}
+
+func NewTerminalHandler(catalog *usecase.TerminalCatalog) *TerminalHandler {
+	return &TerminalHandler{catalog: catalog, validate: validator.New()}
+}
+
+type terminalRequest struct {
+	Code           string   `json:"code" validate:"required,max=20"`
+	Title          string   `json:"title" validate:"required,max=200"`
+	Address        string   `json:"address" validate:"max=300"`
+	LogoURL        string   `json:"logo_url" validate:"omitempty,url,max=500"`
+	Aliases        []string `json:"aliases" validate:"max=20,dive,required,max=150"`
+	FreeTimePolicy string   `json:"free_time_policy" validate:"omitempty,oneof=calendar_days business_days"`
+	Timezone       string   `json:"timezone" validate:"max=64"`
+}
+
+type terminalDTO struct {
+	ID             int64    `json:"id"`
+	Code           string   `json:"code"`
+	Title          string   `json:"title"`
+	Address        string   `json:"address"`
+	LogoURL        string   `json:"logo_url"`
+	Aliases        []string `json:"aliases"`
+	FreeTimePolicy string   `json:"free_time_policy"`
+	Timezone       string   `json:"timezone,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+type terminalListResponse struct {
+	Terminals []terminalDTO `json:"terminals"`
+}
+
+func (h *TerminalHandler) List(w http.ResponseWriter, r *http.Request) {
+	terminals, err := h.catalog.List(r.Context())
+	if err != nil {
+		problem.Write(w, r, problem.Internal("failed to list terminals"))
+		return
+	}
+	resp := terminalListResponse{Terminals: make([]terminalDTO, 0, len(terminals))}
+	for _, t := range terminals {
+		resp.Terminals = append(resp.Terminals, toTerminalDTO(t))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *TerminalHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := terminalID(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.catalog.Get(r.Context(), id)
+	if err != nil {
+		writeTerminalError(w, r, err, "failed to load terminal")
+		return
+	}
+	writeTerminal(w, http.StatusOK, t)
+}
+
+func (h *TerminalHandler) Create(w http.ResponseWriter, r *http.Request) {
+	in, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.catalog.Create(r.Context(), in)
+	if err != nil {
+		writeTerminalError(w, r, err, "failed to create terminal")
+		return
+	}
+	w.Header().Set("Location", "/v1/terminals/"+strconv.FormatInt(t.ID, 10))
+	writeTerminal(w, http.StatusCreated, t)
+}
+
+// Update replaces every field and alias of a terminal.
+func (h *TerminalHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := terminalID(w, r)
+	if !ok {
+		return
+	}
+	in, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.catalog.Update(r.Context(), id, in)
+	if err != nil {
+		writeTerminalError(w, r, err, "failed to update terminal")
+		return
+	}
+	writeTerminal(w, http.StatusOK, t)
+}
+
+func (h *TerminalHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := terminalID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.catalog.Delete(r.Context(), id); err != nil {
+		writeTerminalError(w, r, err, "failed to delete terminal")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TerminalHandler) decode(w http.ResponseWriter, r *http.Request) (usecase.TerminalInput, bool) {
+	var req terminalRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			problem.Write(w, r, problem.BadRequest("empty body"))
+			return usecase.TerminalInput{}, false
+		}
+		problem.Write(w, r, problem.BadRequest("invalid json payload"))
+		return usecase.TerminalInput{}, false
+	}
+	if dec.More() {
+		problem.Write(w, r, problem.BadRequest("multiple json values are not allowed"))
+		return usecase.TerminalInput{}, false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.Write(w, r, problem.BadRequest("payload validation failed"))
+		return usecase.TerminalInput{}, false
+	}
+	return usecase.TerminalInput(req), true
+}
+
+func terminalID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		problem.Write(w, r, problem.BadRequest("terminal id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
+
+func writeTerminalError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		problem.Write(w, r, problem.BadRequest(strings.TrimPrefix(err.Error(), domain.ErrInvalidInput.Error()+": ")))
+	case errors.Is(err, domain.ErrConflict):
+		problem.Write(w, r, problem.Conflict("code or alias already belongs to another terminal"))
+	case errors.Is(err, domain.ErrNotFound):
+		problem.Write(w, r, problem.NotFound("terminal not found"))
+	default:
+		problem.Write(w, r, problem.Internal(fallback))
+	}
+}
+
+func writeTerminal(w http.ResponseWriter, status int, t domain.Terminal) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(toTerminalDTO(t))
+}
+
+func toTerminalDTO(t domain.Terminal) terminalDTO {
+	aliases := t.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	policy := t.FreeTimePolicy
+	if policy == "" {
+		policy = domain.FreeTimeCalendarDays
+	}
+	return terminalDTO{
+		ID:             t.ID,
+		Code:           t.Code,
+		Title:          t.Title,
+		Address:        t.Address,
+		LogoURL:        t.LogoURL,
+		Aliases:        aliases,
+		FreeTimePolicy: string(policy),
+		Timezone:       t.Timezone,
+		CreatedAt:      t.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:      t.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
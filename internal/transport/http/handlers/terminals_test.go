@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/usecase"
+)
+
+type terminalTestRepo struct {
+	terminals []domain.Terminal
+}
+
+func (r *terminalTestRepo) List(_ context.Context) ([]domain.Terminal, error) {
+	return r.terminals, nil
+}
+
+func (r *terminalTestRepo) FindByID(_ context.Context, id int64) (domain.Terminal, error) {
+	for _, t := range r.terminals {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return domain.Terminal{}, domain.ErrNotFound
+}
+
+func (r *terminalTestRepo) Insert(_ context.Context, t domain.Terminal) (int64, error) {
+	for _, existing := range r.terminals {
+		if existing.Code == t.Code {
+			return 0, domain.ErrConflict
+		}
+	}
+	t.ID = int64(len(r.terminals) + 1)
+	r.terminals = append(r.terminals, t)
+	return t.ID, nil
+}
+
+func (r *terminalTestRepo) Update(_ context.Context, _ domain.Terminal) error {
+	return domain.ErrNotFound
+}
+
+func (r *terminalTestRepo) Delete(_ context.Context, _ int64) error {
+	return domain.ErrNotFound
+}
+
+func TestTerminalHandlerCreateAndList(t *testing.T) {
+	catalog := usecase.NewTerminalCatalog(&terminalTestRepo{}, time.Minute)
+	h := NewTerminalHandler(catalog)
+	body := `{"code":"parod","title":"PANAMA PORTS COMPANY (RODMAN)","address":"Rodman, Panama","aliases":["Rodman"]}`
+
+	w := httptest.NewRecorder()
+	h.Create(w, httptest.NewRequest(http.MethodPost, "/v1/terminals", bytes.NewBufferString(body)))
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/v1/terminals/1" {
+		t.Fatalf("expected 201 with location, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.Create(w, httptest.NewRequest(http.MethodPost, "/v1/terminals", bytes.NewBufferString(body)))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate code, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/v1/terminals", nil))
+	var resp terminalListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Terminals) != 1 || resp.Terminals[0].Code != "PAROD" || resp.Terminals[0].Aliases[0] != "RODMAN" {
+		t.Fatalf("unexpected terminals: %+v", resp.Terminals)
+	}
+}
+
+func TestTerminalHandlerUpdateUnknownIsNotFound(t *testing.T) {
+	h := NewTerminalHandler(usecase.NewTerminalCatalog(&terminalTestRepo{}, time.Minute))
+	r := httptest.NewRequest(http.MethodPut, "/v1/terminals/9", bytes.NewBufferString(`{"code":"PAROD","title":"RODMAN"}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "9")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	h.Update(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
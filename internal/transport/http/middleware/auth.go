@@ -35,6 +35,40 @@ func AuthBearer(validator *auth.JWTValidator) func(http.Handler) http.Handler {
 	}
 }
 
+// OptionalAuthBearer attaches claims when a valid bearer token is sent and lets anonymous
+// requests through; a malformed or invalid token is still rejected.
+func OptionalAuthBearer(validator *auth.JWTValidator) func(http.Handler) http.Handler {
+	required := AuthBearer(validator)
+	return func(next http.Handler) http.Handler {
+		authenticated := required(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.TrimSpace(r.Header.Get("Authorization")) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope rejects requests whose claims do not include scope. It must run after AuthBearer.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := ClaimsFromContext(r.Context())
+			if err != nil {
+				problem.Write(w, r, problem.Unauthorized("auth claims missing"))
+				return
+			}
+			if !claims.HasScope(scope) {
+				problem.Write(w, r, problem.Forbidden("missing scope "+scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func WithClaims(ctx context.Context, claims *auth.Claims) context.Context {
 	return context.WithValue(ctx, claimsContextKey, claims)
 }
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/pkg/problem"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-generated key that identifies retries of a request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from the idempotency store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency replays the stored response when a request is retried with the same
+// Idempotency-Key and payload within window. Keys are scoped per authenticated subject, so it
+// must run after AuthBearer. Requests without the header pass through untouched; server errors
+// are not stored so the client can retry them.
+func Idempotency(repo domain.IdempotencyRepository, window time.Duration) func(http.Handler) http.Handler {
+	return idempotency(repo, window, time.Now)
+}
+
+// idempotency is Idempotency with the clock used to stamp and expire reservations.
+func idempotency(repo domain.IdempotencyRepository, window time.Duration, nowFn func() time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !validIdempotencyKey(key) {
+				problem.Write(w, r, problem.BadRequest("Idempotency-Key must be 1-255 printable ASCII characters"))
+				return
+			}
+			claims, err := ClaimsFromContext(r.Context())
+			if err != nil {
+				problem.Write(w, r, problem.Unauthorized("auth claims missing"))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				problem.Write(w, r, problem.BadRequest("failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := nowFn().UTC()
+			entry := domain.IdempotentRequest{
+				Subject:     claims.Subject,
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(window),
+			}
+			if err := repo.Reserve(r.Context(), entry); err != nil {
+				if errors.Is(err, domain.ErrConflict) {
+					replayIdempotent(w, r, repo, entry)
+					return
+				}
+				problem.Write(w, r, problem.ServiceUnavailable("idempotency store unavailable"))
+				return
+			}
+
+			// The response is already sent; store it even if the client went away.
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				// A panicking handler never completes the entry; free the key so the client can
+				// retry instead of getting 409 until the window ends.
+				if p := recover(); p != nil {
+					_ = repo.Release(ctx, entry.Subject, entry.Key)
+					panic(p)
+				}
+			}()
+
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				_ = repo.Release(ctx, entry.Subject, entry.Key)
+				return
+			}
+			if err := repo.Complete(ctx, entry.Subject, entry.Key, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+				_ = repo.Release(ctx, entry.Subject, entry.Key)
+			}
+		})
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, r *http.Request, repo domain.IdempotencyRepository, entry domain.IdempotentRequest) {
+	stored, err := repo.Find(r.Context(), entry.Subject, entry.Key, entry.CreatedAt)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		problem.Write(w, r, problem.Conflict("Idempotency-Key was released concurrently; retry the request"))
+		return
+	case err != nil:
+		problem.Write(w, r, problem.ServiceUnavailable("idempotency store unavailable"))
+		return
+	}
+	if stored.RequestHash != entry.RequestHash {
+		problem.Write(w, r, problem.UnprocessableEntity("Idempotency-Key was already used with a different payload"))
+		return
+	}
+	if stored.StatusCode == 0 {
+		problem.Write(w, r, problem.Conflict("a request with this Idempotency-Key is still being processed"))
+		return
+	}
+
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	_, _ = w.Write(stored.Body)
+}
+
+// requestHash fingerprints the method, path and payload. JSON bodies are re-encoded so retries
+// that only differ in whitespace or key order are treated as the same payload.
+func requestHash(r *http.Request, body []byte) string {
+	payload := body
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err == nil {
+		if canonical, err := json.Marshal(decoded); err == nil {
+			payload = canonical
+		}
+	}
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingWriter passes the response through while keeping a copy for the idempotency store.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+	"github.com/example/validacion-pases/internal/security/auth"
+)
+
+type memoryIdempotency struct {
+	entries map[string]domain.IdempotentRequest
+}
+
+func (m *memoryIdempotency) Reserve(_ context.Context, req domain.IdempotentRequest) error {
+	if stored, ok := m.entries[req.Subject+"|"+req.Key]; ok && stored.ExpiresAt.After(req.CreatedAt) {
+		return domain.ErrConflict
+	}
+	m.entries[req.Subject+"|"+req.Key] = req
+	return nil
+}
+
+func (m *memoryIdempotency) Find(_ context.Context, subject, key string, now time.Time) (domain.IdempotentRequest, error) {
+	req, ok := m.entries[subject+"|"+key]
+	if !ok || !req.ExpiresAt.After(now) {
+		return domain.IdempotentRequest{}, domain.ErrNotFound
+	}
+	return req, nil
+}
+
+func (m *memoryIdempotency) Complete(_ context.Context, subject, key string, statusCode int, contentType string, body []byte) error {
+	req := m.entries[subject+"|"+key]
+	req.StatusCode, req.ContentType, req.Body = statusCode, contentType, body
+	m.entries[subject+"|"+key] = req
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, subject, key string) error {
+	delete(m.entries, subject+"|"+key)
+	return nil
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	store := &memoryIdempotency{entries: map[string]domain.IdempotentRequest{}}
+	calls := 0
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1,"echo":` + string(body) + `}`))
+	}))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/records", strings.NewReader(body))
+		r.Header.Set(IdempotencyKeyHeader, "scan-42")
+		r = r.WithContext(WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	first := send(`{"nave":"A","viaje":"1"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", first.Code)
+	}
+
+	retry := send(`{ "viaje": "1", "nave": "A" }`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected replayed 201, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected replay headers: %v", retry.Header())
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+
+	mismatch := send(`{"nave":"B","viaje":"1"}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", mismatch.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	store := &memoryIdempotency{entries: map[string]domain.IdempotentRequest{}}
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/records", strings.NewReader(`{}`))
+	r.Header.Set(IdempotencyKeyHeader, "scan-43")
+	r = r.WithContext(WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(store.entries) != 0 {
+		t.Fatalf("expected reservation to be released, got %+v", store.entries)
+	}
+}
+
+func TestIdempotencyInProgressIsConflict(t *testing.T) {
+	store := &memoryIdempotency{entries: map[string]domain.IdempotentRequest{}}
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("handler must not run while the key is reserved")
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/records", strings.NewReader(`{}`))
+	store.entries["gate-1|scan-44"] = domain.IdempotentRequest{Subject: "gate-1", Key: "scan-44", RequestHash: requestHash(r, []byte(`{}`)), ExpiresAt: time.Now().Add(time.Hour)}
+	r.Header.Set(IdempotencyKeyHeader, "scan-44")
+	r = r.WithContext(WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	store := &memoryIdempotency{entries: map[string]domain.IdempotentRequest{}}
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/records", strings.NewReader(`{}`))
+	r.Header.Set(IdempotencyKeyHeader, "scan-45")
+	r = r.WithContext(WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Fatalf("expected the panic to propagate, got %v", p)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}()
+
+	if len(store.entries) != 0 {
+		t.Fatalf("expected reservation to be released, got %+v", store.entries)
+	}
+}
+
+func TestIdempotencyKeyExpiresAfterWindow(t *testing.T) {
+	store := &memoryIdempotency{entries: map[string]domain.IdempotentRequest{}}
+	now := time.Date(2026, 2, 17, 9, 0, 0, 0, time.UTC)
+	calls := 0
+	h := idempotency(store, time.Hour, func() time.Time { return now })(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/records", strings.NewReader(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "scan-46")
+		r = r.WithContext(WithClaims(r.Context(), &auth.Claims{Subject: "gate-1"}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	send()
+	now = now.Add(59 * time.Minute)
+	if w := send(); w.Header().Get(IdempotentReplayedHeader) != "true" || calls != 1 {
+		t.Fatalf("expected a replay within the window, got %d calls", calls)
+	}
+	now = now.Add(2 * time.Minute)
+	if w := send(); w.Header().Get(IdempotentReplayedHeader) != "" || calls != 2 {
+		t.Fatalf("expected the request to run again after the window, got %d calls", calls)
+	}
+}
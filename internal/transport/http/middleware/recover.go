@@ -11,6 +11,10 @@ func Recoverer(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					// Deliberate abort of a response already in flight; let net/http drop the connection.
+					panic(rec)
+				}
 				problem.Write(w, r, problem.Internal(fmt.Sprintf("panic recovered: %v", rec)))
 			}
 		}()
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// ClientTariffs finds the tariff of new passes. Tariffs are read from the repository and cached
+// for ttl (0 keeps them for the life of the process).
+type ClientTariffs struct {
+	repo  domain.ClientTariffRepository
+	ttl   time.Duration
+	nowFn func() time.Time
+
+	mu       sync.RWMutex
+	byClient map[string][]domain.ClientTariff
+	loadedAt time.Time
+}
+
+func NewClientTariffs(repo domain.ClientTariffRepository, ttl time.Duration) *ClientTariffs {
+	return &ClientTariffs{repo: repo, ttl: ttl, nowFn: time.Now}
+}
+
+// Find returns the tariff covering rec. The most specific tariff wins: one for the container
+// type over one for the rama over one for any pass; among equals, the one valid from the latest
+// date.
+func (c *ClientTariffs) Find(ctx context.Context, rec domain.Record) (domain.ClientTariff, bool, error) {
+	byClient, err := c.load(ctx)
+	if err != nil {
+		return domain.ClientTariff{}, false, err
+	}
+	var types []string
+	for _, line := range rec.Containers {
+		if line.CodigoISO == "" {
+			types = nil
+			break
+		}
+		types = append(types, line.CodigoISO)
+	}
+
+	var best domain.ClientTariff
+	found := false
+	for _, t := range byClient[clientKey(rec.Cliente)] {
+		if !t.Applies(rec.Rama, types, rec.BaseFechaReal()) {
+			continue
+		}
+		if !found || tariffRank(t) > tariffRank(best) ||
+			(tariffRank(t) == tariffRank(best) && t.ValidFrom.After(best.ValidFrom)) {
+			best, found = t, true
+		}
+	}
+	return best, found, nil
+}
+
+func tariffRank(t domain.ClientTariff) int {
+	rank := 0
+	if t.ContainerType != "" {
+		rank += 2
+	}
+	if t.Rama != "" {
+		rank++
+	}
+	return rank
+}
+
+func (c *ClientTariffs) load(ctx context.Context) (map[string][]domain.ClientTariff, error) {
+	c.mu.RLock()
+	byClient, loadedAt := c.byClient, c.loadedAt
+	c.mu.RUnlock()
+	if !loadedAt.IsZero() && (c.ttl <= 0 || c.nowFn().Sub(loadedAt) < c.ttl) {
+		return byClient, nil
+	}
+
+	tariffs, err := c.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byClient = make(map[string][]domain.ClientTariff)
+	for _, t := range tariffs {
+		key := clientKey(t.Cliente)
+		byClient[key] = append(byClient[key], t)
+	}
+
+	c.mu.Lock()
+	c.byClient, c.loadedAt = byClient, c.nowFn()
+	c.mu.Unlock()
+	return byClient, nil
+}
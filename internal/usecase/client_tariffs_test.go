@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type stubTariffRepo []domain.ClientTariff
+
+func (s stubTariffRepo) List(_ context.Context) ([]domain.ClientTariff, error) { return s, nil }
+
+func newTariffService(stored *domain.Record) *RecordService {
+	tariffs := stubTariffRepo{
+		{ID: 1, Cliente: "CAPITAL PACIFICO, S.A.", DefaultDiasLibre: 5, MaxDiasLibre: 10, ValidFrom: day("2026-01-01")},
+		{ID: 2, Cliente: "Capital Pacifico, S.A.", Rama: "nacional", ContainerType: "45G1", DefaultDiasLibre: 7, MaxDiasLibre: 14, ValidFrom: day("2026-01-01")},
+		{ID: 3, Cliente: "CAPITAL PACIFICO, S.A.", DefaultDiasLibre: 3, MaxDiasLibre: 4, ValidFrom: day("2025-01-01"), ValidTo: day("2025-12-31")},
+	}
+	return NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+		*stored = r
+		return 1, nil
+	}}).WithTariffs(NewClientTariffs(tariffs, time.Minute))
+}
+
+func TestCreateFillsDiasLibreFromTariff(t *testing.T) {
+	var stored domain.Record
+	svc := newTariffService(&stored)
+	base := domain.CreateRecordInput{
+		Nave: "NYK DENEB", Viaje: "072E", Cliente: "CAPITAL PACIFICO, S.A.", Booking: "BK1",
+		PuertoDescargue: "BALBOA", UsuarioFirma: "user-1",
+	}
+
+	cases := []struct {
+		name      string
+		fechaReal string
+		lines     []domain.ContainerLine
+		rama      string
+		want      int
+	}{
+		{"general tariff", "2026-02-09", []domain.ContainerLine{{Serie: "YMLU5374938"}}, "", 5},
+		{"container type tariff", "2026-02-09", []domain.ContainerLine{{CodigoISO: "45g1"}}, "nacional", 7},
+		{"mixed types fall back", "2026-02-09", []domain.ContainerLine{{CodigoISO: "45G1"}, {CodigoISO: "22G1"}}, "nacional", 5},
+		{"expired tariff ignored", "2025-06-01", []domain.ContainerLine{{Serie: "YMLU5374938"}}, "", 3},
+	}
+	for _, tc := range cases {
+		in := base
+		in.FechaReal, in.Containers, in.Rama = day(tc.fechaReal), tc.lines, tc.rama
+		if tc.rama == "nacional" {
+			in.Transportista = "GLOBERUNNERS, INC"
+		}
+		if _, _, err := svc.Create(context.Background(), in); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if stored.DiasLibre != tc.want || !stored.LibreRetencionHasta.Equal(stored.FechaReal.AddDate(0, 0, tc.want)) {
+			t.Errorf("%s: expected %d days, got %d until %s", tc.name, tc.want, stored.DiasLibre, stored.LibreRetencionHasta.Format("2006-01-02"))
+		}
+	}
+
+	other := base
+	other.Cliente, other.FechaReal, other.ContenedorSerie = "OTRO CLIENTE", day("2026-02-09"), "YMLU5374938"
+	if _, _, err := svc.Create(context.Background(), other); err != nil || stored.DiasLibre != 0 {
+		t.Fatalf("expected no tariff for other clients, got %d days (%v)", stored.DiasLibre, err)
+	}
+}
+
+func TestCreateRejectsDiasLibreAboveTariff(t *testing.T) {
+	var stored domain.Record
+	svc := newTariffService(&stored)
+	dias := 12
+	in := domain.CreateRecordInput{
+		Nave: "NYK DENEB", Viaje: "072E", Cliente: "CAPITAL PACIFICO, S.A.", Booking: "BK1", ContenedorSerie: "YMLU5374938",
+		FechaReal: day("2026-02-09"), DiasLibre: &dias, PuertoDescargue: "BALBOA", UsuarioFirma: "user-1",
+	}
+
+	_, _, err := svc.Create(context.Background(), in)
+	var limitErr *domain.TariffLimitError
+	if !errors.Is(err, domain.ErrInvalidInput) || !errors.As(err, &limitErr) || limitErr.Max != 10 {
+		t.Fatalf("expected TariffLimitError with max 10, got %v", err)
+	}
+
+	in.OverrideTariff = true
+	if _, _, err := svc.Create(context.Background(), in); err != nil || stored.DiasLibre != 12 {
+		t.Fatalf("expected the override to keep 12 days, got %d (%v)", stored.DiasLibre, err)
+	}
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// FreeTimeCalculator computes the last day of free retention for a container discharged on
+// fechaReal with diasLibre free days.
+type FreeTimeCalculator interface {
+	Until(fechaReal time.Time, diasLibre int) time.Time
+}
+
+// CalendarDays counts every day.
+type CalendarDays struct{}
+
+func (CalendarDays) Until(fechaReal time.Time, diasLibre int) time.Time {
+	return freeTimeUntil(fechaReal, diasLibre)
+}
+
+// BusinessDays counts only Monday to Friday outside Holidays: the result is the diasLibre-th
+// working day after fechaReal.
+type BusinessDays struct {
+	Holidays HolidayCalendar
+}
+
+func (b BusinessDays) Until(fechaReal time.Time, diasLibre int) time.Time {
+	day := fechaReal
+	for counted := 0; counted < diasLibre; {
+		day = day.AddDate(0, 0, 1)
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !b.Holidays.IsHoliday(day) {
+			counted++
+		}
+	}
+	return day
+}
+
+// HolidayCalendar answers whether a date is a holiday.
+type HolidayCalendar struct {
+	dates  map[string]bool
+	yearly map[string]int // "01-09" -> first year it applies
+}
+
+// NewHolidayCalendar builds the calendar of terminalCode from holidays, keeping the national ones
+// (empty TerminalCode) and those of the terminal.
+func NewHolidayCalendar(holidays []domain.Holiday, terminalCode string) HolidayCalendar {
+	c := HolidayCalendar{dates: map[string]bool{}, yearly: map[string]int{}}
+	for _, h := range holidays {
+		if h.TerminalCode != "" && !strings.EqualFold(h.TerminalCode, terminalCode) {
+			continue
+		}
+		if !h.Yearly {
+			c.dates[h.Date.Format("2006-01-02")] = true
+			continue
+		}
+		key := h.Date.Format("01-02")
+		if first, ok := c.yearly[key]; !ok || h.Date.Year() < first {
+			c.yearly[key] = h.Date.Year()
+		}
+	}
+	return c
+}
+
+func (c HolidayCalendar) IsHoliday(day time.Time) bool {
+	if c.dates[day.Format("2006-01-02")] {
+		return true
+	}
+	first, ok := c.yearly[day.Format("01-02")]
+	return ok && day.Year() >= first
+}
+
+// FreeTimeRules selects the free-time policy of new passes and builds calculators with the
+// holiday calendar of each terminal. Holidays and client policies are read from the repository
+// and cached for ttl (0 keeps them for the life of the process); holidays given to
+// NewFreeTimeRules, e.g. from ICS files, are added to those of the repository.
+type FreeTimeRules struct {
+	repo  domain.FreeTimeRepository
+	extra []domain.Holiday
+	ttl   time.Duration
+	nowFn func() time.Time
+
+	mu       sync.RWMutex
+	holidays []domain.Holiday
+	clients  map[string]domain.FreeTimePolicy
+	loadedAt time.Time
+}
+
+// NewFreeTimeRules reads holidays and client policies from repo, which may be nil when extra are
+// the only holidays and policies come from the terminals alone.
+func NewFreeTimeRules(repo domain.FreeTimeRepository, ttl time.Duration, extra ...domain.Holiday) *FreeTimeRules {
+	return &FreeTimeRules{repo: repo, extra: extra, ttl: ttl, nowFn: time.Now}
+}
+
+// Select returns the policy for a new pass of cliente at terminal: the client's policy, else the
+// terminal's, else calendar days.
+func (r *FreeTimeRules) Select(ctx context.Context, cliente string, terminal domain.Terminal) (domain.FreeTimePolicy, error) {
+	_, clients, err := r.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := clients[clientKey(cliente)]; ok {
+		return p, nil
+	}
+	if terminal.FreeTimePolicy != "" {
+		return terminal.FreeTimePolicy, nil
+	}
+	return domain.FreeTimeCalendarDays, nil
+}
+
+// Calculator returns the calculator for policy at the terminal with terminalCode (empty when the
+// port has no catalogue terminal, so only national holidays apply).
+func (r *FreeTimeRules) Calculator(ctx context.Context, policy domain.FreeTimePolicy, terminalCode string) (FreeTimeCalculator, error) {
+	switch policy {
+	case "", domain.FreeTimeCalendarDays:
+		return CalendarDays{}, nil
+	case domain.FreeTimeBusinessDays:
+		holidays, _, err := r.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return BusinessDays{Holidays: NewHolidayCalendar(holidays, terminalCode)}, nil
+	default:
+		return nil, fmt.Errorf("unknown free-time policy %q", policy)
+	}
+}
+
+func (r *FreeTimeRules) load(ctx context.Context) ([]domain.Holiday, map[string]domain.FreeTimePolicy, error) {
+	r.mu.RLock()
+	holidays, clients, loadedAt := r.holidays, r.clients, r.loadedAt
+	r.mu.RUnlock()
+	if !loadedAt.IsZero() && (r.ttl <= 0 || r.nowFn().Sub(loadedAt) < r.ttl) {
+		return holidays, clients, nil
+	}
+
+	holidays = append([]domain.Holiday(nil), r.extra...)
+	clients = map[string]domain.FreeTimePolicy{}
+	if r.repo != nil {
+		stored, err := r.repo.ListHolidays(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		holidays = append(holidays, stored...)
+		policies, err := r.repo.ListClientPolicies(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range policies {
+			clients[clientKey(p.Cliente)] = p.Policy
+		}
+	}
+
+	r.mu.Lock()
+	r.holidays, r.clients, r.loadedAt = holidays, clients, r.nowFn()
+	r.mu.Unlock()
+	return holidays, clients, nil
+}
+
+func clientKey(cliente string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(cliente)), " ")
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type stubFreeTimeRepo struct {
+	holidays []domain.Holiday
+	clients  []domain.ClientFreeTimePolicy
+	loads    int
+}
+
+func (s *stubFreeTimeRepo) ListHolidays(_ context.Context) ([]domain.Holiday, error) {
+	s.loads++
+	return s.holidays, nil
+}
+
+func (s *stubFreeTimeRepo) ListClientPolicies(_ context.Context) ([]domain.ClientFreeTimePolicy, error) {
+	return s.clients, nil
+}
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestBusinessDaysSkipWeekendsAndHolidays(t *testing.T) {
+	calendar := NewHolidayCalendar([]domain.Holiday{
+		{Date: day("2026-02-16"), Name: "Carnaval"},
+		{Date: day("2026-02-17"), Name: "Carnaval"},
+		{TerminalCode: "PAMIT", Date: day("2026-02-18"), Name: "Aniversario"},
+		{Date: day("2020-11-03"), Name: "Separación de Colombia", Yearly: true},
+	}, "PABLB")
+
+	cases := []struct {
+		from string
+		days int
+		want string
+	}{
+		{"2026-02-09", 0, "2026-02-09"},
+		{"2026-02-09", 3, "2026-02-12"},
+		{"2026-02-12", 2, "2026-02-18"}, // Fri 13, carnival Mon/Tue, the PAMIT holiday does not apply
+		{"2026-02-14", 1, "2026-02-18"}, // discharged on Saturday
+		{"2026-11-02", 1, "2026-11-04"}, // yearly holiday
+	}
+	for _, tc := range cases {
+		if got := (BusinessDays{Holidays: calendar}).Until(day(tc.from), tc.days); got.Format("2006-01-02") != tc.want {
+			t.Errorf("%s + %d business days: expected %s, got %s", tc.from, tc.days, tc.want, got.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestFreeTimeRulesSelectClientThenTerminal(t *testing.T) {
+	repo := &stubFreeTimeRepo{clients: []domain.ClientFreeTimePolicy{{Cliente: "Capital  Pacifico, S.A.", Policy: domain.FreeTimeBusinessDays}}}
+	rules := NewFreeTimeRules(repo, time.Minute)
+	ctx := context.Background()
+	calendarTerminal := domain.Terminal{Code: "PABLB", FreeTimePolicy: domain.FreeTimeCalendarDays}
+
+	if p, err := rules.Select(ctx, "CAPITAL PACIFICO, S.A.", calendarTerminal); err != nil || p != domain.FreeTimeBusinessDays {
+		t.Fatalf("expected the client policy, got %q (%v)", p, err)
+	}
+	if p, _ := rules.Select(ctx, "OTRO CLIENTE", domain.Terminal{FreeTimePolicy: domain.FreeTimeBusinessDays}); p != domain.FreeTimeBusinessDays {
+		t.Fatalf("expected the terminal policy, got %q", p)
+	}
+	if p, _ := rules.Select(ctx, "OTRO CLIENTE", domain.Terminal{}); p != domain.FreeTimeCalendarDays {
+		t.Fatalf("expected calendar days, got %q", p)
+	}
+	if repo.loads != 1 {
+		t.Fatalf("expected a single load, got %d", repo.loads)
+	}
+}
+
+func TestCreateAndExtendWithBusinessDays(t *testing.T) {
+	repo := &stubFreeTimeRepo{
+		holidays: []domain.Holiday{{Date: day("2026-02-16")}, {Date: day("2026-02-17")}},
+		clients:  []domain.ClientFreeTimePolicy{{Cliente: "CAPITAL PACIFICO, S.A.", Policy: domain.FreeTimeBusinessDays}},
+	}
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{
+		insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+			stored = r
+			return 10, nil
+		},
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) {
+			stored.ID, stored.Status, stored.Version = 10, domain.StatusIssued, 1
+			return stored, nil
+		},
+		amendFn: func(_ context.Context, r domain.Record, _ int, _ domain.RecordVersion) error {
+			stored = r
+			return nil
+		},
+	}).WithFreeTime(NewFreeTimeRules(repo, 0)).WithExtensions(&mockExtensions{})
+
+	dias := 4
+	_, rec, err := svc.Create(context.Background(), domain.CreateRecordInput{
+		Nave: "NYK DENEB", Viaje: "072E", Cliente: "CAPITAL PACIFICO, S.A.", Booking: "BK1", ContenedorSerie: "YMLU5374938",
+		FechaReal: day("2026-02-09"), DiasLibre: &dias, PuertoDescargue: "BALBOA", UsuarioFirma: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.FreeTimePolicy != domain.FreeTimeBusinessDays || rec.LibreRetencionHasta.Format("2006-01-02") != "2026-02-13" {
+		t.Fatalf("unexpected free time: %s until %s", rec.FreeTimePolicy, rec.LibreRetencionHasta.Format("2006-01-02"))
+	}
+
+	ext, _, err := svc.Extend(context.Background(), 10, 1, "negociado", "gerente", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ext.NewUntil.Format("2006-01-02"); got != "2026-02-18" {
+		t.Fatalf("expected the extension to skip carnival, got %s", got)
+	}
+}
+
+func TestAmendKeepsFreeTimeUnlessItsInputsChange(t *testing.T) {
+	// The holiday was published after the pass was issued with 2026-02-12 as its deadline.
+	repo := &stubFreeTimeRepo{holidays: []domain.Holiday{{Date: day("2026-02-11"), Name: "Feriado nuevo"}}}
+	issued := amendableRecord()
+	issued.FreeTimePolicy = domain.FreeTimeBusinessDays
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return issued, nil },
+		amendFn: func(_ context.Context, r domain.Record, _ int, _ domain.RecordVersion) error {
+			stored = r
+			return nil
+		},
+	}).WithFreeTime(NewFreeTimeRules(repo, 0))
+
+	nave := "NYK DENEB"
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{Nave: &nave, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-12" || !stored.LibreRetencionHasta.Equal(issued.LibreRetencionHasta) {
+		t.Fatalf("a typo fix must keep the issued deadline, got %s", got)
+	}
+
+	dias := 4
+	rec, err = svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{DiasLibre: &dias, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-16" {
+		t.Fatalf("expected the new dias_libre to skip the holiday, got %s", got)
+	}
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidQRKeyring = errors.New("invalid qr keyring")
+	// ErrRetiredQRSigningKey is returned for tokens signed by a retired key after its verification cutoff.
+	ErrRetiredQRSigningKey = fmt.Errorf("%w: signing key retired", ErrExpiredQRToken)
+)
+
+var qrKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// QRSigningKey is one entry of a QR keyring. Version "v1" uses Secret (HMAC-SHA256),
+// version "v2" uses PrivateKey/PublicKey (Ed25519).
+type QRSigningKey struct {
+	ID         string
+	Version    string
+	Secret     []byte
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	// VerifyUntil is the cutoff after which tokens signed with a retired key are rejected.
+	// The zero value means no cutoff. It is ignored for the active key.
+	VerifyUntil time.Time
+}
+
+// QRKeyring holds the active signing key plus retired keys still accepted for verification.
+type QRKeyring struct {
+	activeID string
+	keys     map[string]QRSigningKey
+}
+
+func NewQRKeyring(activeID string, keys []QRSigningKey) (*QRKeyring, error) {
+	kr := &QRKeyring{activeID: strings.TrimSpace(activeID), keys: make(map[string]QRSigningKey, len(keys))}
+	for _, key := range keys {
+		if !qrKeyIDPattern.MatchString(key.ID) {
+			return nil, fmt.Errorf("%w: key id %q must match %s", ErrInvalidQRKeyring, key.ID, qrKeyIDPattern)
+		}
+		if _, dup := kr.keys[key.ID]; dup {
+			return nil, fmt.Errorf("%w: duplicate key id %q", ErrInvalidQRKeyring, key.ID)
+		}
+		switch key.Version {
+		case "v1":
+			if len(key.Secret) == 0 {
+				return nil, fmt.Errorf("%w: key %q has no secret", ErrInvalidQRKeyring, key.ID)
+			}
+		case "v2":
+			if len(key.PublicKey) != ed25519.PublicKeySize && len(key.PrivateKey) == ed25519.PrivateKeySize {
+				key.PublicKey = key.PrivateKey.Public().(ed25519.PublicKey)
+			}
+			if len(key.PublicKey) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("%w: key %q has no ed25519 public key", ErrInvalidQRKeyring, key.ID)
+			}
+		default:
+			return nil, fmt.Errorf("%w: key %q has unsupported version %q", ErrInvalidQRKeyring, key.ID, key.Version)
+		}
+		kr.keys[key.ID] = key
+	}
+	if kr.activeID != "" {
+		if _, ok := kr.keys[kr.activeID]; !ok {
+			return nil, fmt.Errorf("%w: active key %q is not in the keyring", ErrInvalidQRKeyring, kr.activeID)
+		}
+	}
+	return kr, nil
+}
+
+// KeyringQRTokenIssuer signs tokens carrying the active key id: `<version>.<kid>.<id>.<exp>.<sig>`.
+type KeyringQRTokenIssuer struct {
+	keyring    *QRKeyring
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	nowFn      func() time.Time
+}
+
+func NewKeyringQRTokenIssuer(keyring *QRKeyring, defaultTTL, maxTTL time.Duration) *KeyringQRTokenIssuer {
+	return &KeyringQRTokenIssuer{keyring: keyring, defaultTTL: defaultTTL, maxTTL: maxTTL, nowFn: time.Now}
+}
+
+func (i *KeyringQRTokenIssuer) Issue(recordID int64, ttl time.Duration) (string, time.Time, error) {
+	if i.keyring == nil || i.keyring.activeID == "" {
+		return "", time.Time{}, ErrQRIssuerUnavailable
+	}
+	key := i.keyring.keys[i.keyring.activeID]
+	if key.Version == "v2" && len(key.PrivateKey) != ed25519.PrivateKeySize {
+		return "", time.Time{}, ErrQRIssuerUnavailable
+	}
+	if recordID <= 0 {
+		return "", time.Time{}, ErrInvalidQRToken
+	}
+	if ttl == 0 {
+		ttl = i.defaultTTL
+	}
+	if ttl <= 0 || (i.maxTTL > 0 && ttl > i.maxTTL) {
+		return "", time.Time{}, ErrInvalidQRTokenTTL
+	}
+
+	expiresAt := i.nowFn().UTC().Add(ttl).Truncate(time.Second)
+	exp := expiresAt.Unix()
+	body := keyedTokenBody(key.Version, key.ID, recordID, exp)
+	var sig []byte
+	if key.Version == "v2" {
+		sig = ed25519.Sign(key.PrivateKey, body)
+	} else {
+		sig = truncatedHMAC(key.Secret, body)
+	}
+	token := fmt.Sprintf("%s.%s.%d.%d.%s", key.Version, key.ID, recordID, exp, base64.RawURLEncoding.EncodeToString(sig))
+	return token, expiresAt, nil
+}
+
+// KeyringQRTokenVerifier verifies tokens that carry a key id against the keyring and
+// delegates tokens without one to the legacy single-key verifier.
+type KeyringQRTokenVerifier struct {
+	keyring *QRKeyring
+	legacy  QRTokenVerifier
+	nowFn   func() time.Time
+}
+
+func NewKeyringQRTokenVerifier(keyring *QRKeyring, legacy QRTokenVerifier) *KeyringQRTokenVerifier {
+	return &KeyringQRTokenVerifier{keyring: keyring, legacy: legacy, nowFn: time.Now}
+}
+
+func (v *KeyringQRTokenVerifier) VerifyAndExtractRecordID(token string) (int64, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 {
+		if v.legacy == nil {
+			return 0, ErrInvalidQRToken
+		}
+		return v.legacy.VerifyAndExtractRecordID(token)
+	}
+	if v.keyring == nil || len(v.keyring.keys) == 0 {
+		return 0, ErrQRVerifierUnavailable
+	}
+
+	version, kid := parts[0], parts[1]
+	key, ok := v.keyring.keys[kid]
+	if !ok || key.Version != version {
+		return 0, ErrInvalidQRToken
+	}
+
+	recordID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || recordID <= 0 {
+		return 0, ErrInvalidQRToken
+	}
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || exp <= 0 {
+		return 0, ErrInvalidQRToken
+	}
+	provided, err := decodeBase64URL(parts[4])
+	if err != nil {
+		return 0, ErrInvalidQRToken
+	}
+
+	body := keyedTokenBody(version, kid, recordID, exp)
+	switch version {
+	case "v1":
+		if !hmac.Equal(truncatedHMAC(key.Secret, body), provided) {
+			return 0, ErrInvalidQRToken
+		}
+	case "v2":
+		if len(provided) != ed25519.SignatureSize || !ed25519.Verify(key.PublicKey, body, provided) {
+			return 0, ErrInvalidQRToken
+		}
+	default:
+		return 0, ErrInvalidQRToken
+	}
+
+	now := v.nowFn().UTC()
+	if kid != v.keyring.activeID && !key.VerifyUntil.IsZero() && now.After(key.VerifyUntil) {
+		return recordID, ErrRetiredQRSigningKey
+	}
+	if now.Unix() > exp {
+		return recordID, ErrExpiredQRToken
+	}
+
+	return recordID, nil
+}
+
+func keyedTokenBody(version, kid string, recordID, exp int64) []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d|%d", version, kid, recordID, exp))
+}
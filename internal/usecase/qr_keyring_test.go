@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyringRotationAcceptsRetiredKeyUntilCutoff(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	_, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey := QRSigningKey{ID: "2025a", Version: "v1", Secret: []byte("old-secret")}
+	newKey := QRSigningKey{ID: "2026a", Version: "v2", PrivateKey: newPrivate}
+
+	oldRing, err := NewQRKeyring("2025a", []QRSigningKey{oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldIssuer := NewKeyringQRTokenIssuer(oldRing, 48*time.Hour, 0)
+	oldIssuer.nowFn = func() time.Time { return now }
+	oldToken, _, err := oldIssuer.Issue(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(oldToken, "v1.2025a.10.") {
+		t.Fatalf("unexpected token format: %s", oldToken)
+	}
+
+	oldKey.VerifyUntil = now.Add(24 * time.Hour)
+	rotated, err := NewQRKeyring("2026a", []QRSigningKey{newKey, oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := NewKeyringQRTokenIssuer(rotated, time.Hour, 0)
+	issuer.nowFn = func() time.Time { return now }
+	newToken, _, err := issuer.Issue(20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(newToken, "v2.2026a.20.") {
+		t.Fatalf("unexpected token format: %s", newToken)
+	}
+
+	verifier := NewKeyringQRTokenVerifier(rotated, nil)
+	verifier.nowFn = func() time.Time { return now.Add(time.Minute) }
+	if id, err := verifier.VerifyAndExtractRecordID(oldToken); err != nil || id != 10 {
+		t.Fatalf("expected retired key to verify before cutoff, got %d, %v", id, err)
+	}
+	if id, err := verifier.VerifyAndExtractRecordID(newToken); err != nil || id != 20 {
+		t.Fatalf("expected active key to verify, got %d, %v", id, err)
+	}
+
+	verifier.nowFn = func() time.Time { return now.Add(25 * time.Hour) }
+	if _, err := verifier.VerifyAndExtractRecordID(oldToken); !errors.Is(err, ErrRetiredQRSigningKey) {
+		t.Fatalf("expected ErrRetiredQRSigningKey after cutoff, got %v", err)
+	}
+}
+
+func TestKeyringVerifierDelegatesTokensWithoutKeyID(t *testing.T) {
+	secret := "my-qr-secret"
+	ring, err := NewQRKeyring("", []QRSigningKey{{ID: "k1", Version: "v1", Secret: []byte("other")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := NewKeyringQRTokenVerifier(ring, NewCompactQRTokenVerifier(secret))
+
+	token := signCompactToken(45, secret, time.Now().Add(time.Hour).Unix())
+	if id, err := verifier.VerifyAndExtractRecordID(token); err != nil || id != 45 {
+		t.Fatalf("expected legacy token to verify, got %d, %v", id, err)
+	}
+	if _, err := verifier.VerifyAndExtractRecordID("v1.unknown.45.1700000600.abc"); !errors.Is(err, ErrInvalidQRToken) {
+		t.Fatalf("expected ErrInvalidQRToken for unknown key id, got %v", err)
+	}
+
+	if _, _, err := NewKeyringQRTokenIssuer(ring, time.Hour, 0).Issue(45, 0); !errors.Is(err, ErrQRIssuerUnavailable) {
+		t.Fatalf("expected ErrQRIssuerUnavailable without active key, got %v", err)
+	}
+}
+
+func TestNewQRKeyringRejectsInvalidKeys(t *testing.T) {
+	cases := map[string][]QRSigningKey{
+		"bad id":        {{ID: "bad.id", Version: "v1", Secret: []byte("s")}},
+		"duplicate":     {{ID: "k1", Version: "v1", Secret: []byte("s")}, {ID: "k1", Version: "v1", Secret: []byte("t")}},
+		"no secret":     {{ID: "k1", Version: "v1"}},
+		"no public key": {{ID: "k1", Version: "v2"}},
+		"bad version":   {{ID: "k1", Version: "v9", Secret: []byte("s")}},
+	}
+	for name, keys := range cases {
+		if _, err := NewQRKeyring("", keys); !errors.Is(err, ErrInvalidQRKeyring) {
+			t.Errorf("%s: expected ErrInvalidQRKeyring, got %v", name, err)
+		}
+	}
+	if _, err := NewQRKeyring("missing", nil); !errors.Is(err, ErrInvalidQRKeyring) {
+		t.Errorf("missing active key: expected ErrInvalidQRKeyring, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidQRSigningKey = errors.New("invalid qr signing key")
+
+// Ed25519QRTokenIssuer signs v2 compact tokens (`v2.<id>.<exp>.<sig>`) with an Ed25519 private key.
+type Ed25519QRTokenIssuer struct {
+	privateKey ed25519.PrivateKey
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	nowFn      func() time.Time
+}
+
+func NewEd25519QRTokenIssuer(privateKey ed25519.PrivateKey, defaultTTL, maxTTL time.Duration) *Ed25519QRTokenIssuer {
+	return &Ed25519QRTokenIssuer{
+		privateKey: privateKey,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		nowFn:      time.Now,
+	}
+}
+
+func (i *Ed25519QRTokenIssuer) Issue(recordID int64, ttl time.Duration) (string, time.Time, error) {
+	if len(i.privateKey) != ed25519.PrivateKeySize {
+		return "", time.Time{}, ErrQRIssuerUnavailable
+	}
+	if recordID <= 0 {
+		return "", time.Time{}, ErrInvalidQRToken
+	}
+	if ttl == 0 {
+		ttl = i.defaultTTL
+	}
+	if ttl <= 0 || (i.maxTTL > 0 && ttl > i.maxTTL) {
+		return "", time.Time{}, ErrInvalidQRTokenTTL
+	}
+
+	expiresAt := i.nowFn().UTC().Add(ttl).Truncate(time.Second)
+	exp := expiresAt.Unix()
+	sig := ed25519.Sign(i.privateKey, compactTokenBody("v2", recordID, exp))
+	token := fmt.Sprintf("v2.%d.%d.%s", recordID, exp, base64.RawURLEncoding.EncodeToString(sig))
+	return token, expiresAt, nil
+}
+
+// Ed25519QRTokenVerifier verifies v2 compact tokens and only needs the public key,
+// so it can be deployed on gate devices and partners without the ability to forge passes.
+type Ed25519QRTokenVerifier struct {
+	publicKey ed25519.PublicKey
+	nowFn     func() time.Time
+}
+
+func NewEd25519QRTokenVerifier(publicKey ed25519.PublicKey) *Ed25519QRTokenVerifier {
+	return &Ed25519QRTokenVerifier{publicKey: publicKey, nowFn: time.Now}
+}
+
+func (v *Ed25519QRTokenVerifier) VerifyAndExtractRecordID(token string) (int64, error) {
+	if len(v.publicKey) != ed25519.PublicKeySize {
+		return 0, ErrQRVerifierUnavailable
+	}
+
+	parsed, err := parseCompactToken(token, "v2")
+	if err != nil {
+		return 0, err
+	}
+	if len(parsed.signature) != ed25519.SignatureSize {
+		return 0, ErrInvalidQRToken
+	}
+	if !ed25519.Verify(v.publicKey, compactTokenBody(parsed.version, parsed.recordID, parsed.exp), parsed.signature) {
+		return 0, ErrInvalidQRToken
+	}
+
+	if v.nowFn().UTC().Unix() > parsed.exp {
+		return parsed.recordID, ErrExpiredQRToken
+	}
+
+	return parsed.recordID, nil
+}
+
+// ParseEd25519PrivateKey accepts a PKCS#8 PEM block or the base64 encoding of a 32-byte seed
+// or a 64-byte private key.
+func ParseEd25519PrivateKey(raw string) (ed25519.PrivateKey, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "-----BEGIN") {
+		block, _ := pem.Decode([]byte(raw))
+		if block == nil {
+			return nil, ErrInvalidQRSigningKey
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidQRSigningKey
+		}
+		privateKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidQRSigningKey
+		}
+		return privateKey, nil
+	}
+
+	decoded, err := decodeBase64Key(raw)
+	if err != nil {
+		return nil, ErrInvalidQRSigningKey
+	}
+	switch len(decoded) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(decoded), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(decoded), nil
+	default:
+		return nil, ErrInvalidQRSigningKey
+	}
+}
+
+// ParseEd25519PublicKey accepts a PKIX PEM block or the base64 encoding of a 32-byte public key.
+func ParseEd25519PublicKey(raw string) (ed25519.PublicKey, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "-----BEGIN") {
+		block, _ := pem.Decode([]byte(raw))
+		if block == nil {
+			return nil, ErrInvalidQRSigningKey
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidQRSigningKey
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, ErrInvalidQRSigningKey
+		}
+		return publicKey, nil
+	}
+
+	decoded, err := decodeBase64Key(raw)
+	if err != nil || len(decoded) != ed25519.PublicKeySize {
+		return nil, ErrInvalidQRSigningKey
+	}
+	return ed25519.PublicKey(decoded), nil
+}
+
+func decodeBase64Key(raw string) ([]byte, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(raw); err == nil {
+		return decoded, nil
+	}
+	return decodeBase64URL(strings.TrimRight(raw, "="))
+}
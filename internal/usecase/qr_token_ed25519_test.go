@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEd25519QRTokenRoundTrip(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0).UTC()
+
+	issuer := NewEd25519QRTokenIssuer(privateKey, 10*time.Minute, time.Hour)
+	issuer.nowFn = func() time.Time { return now }
+	verifier := NewEd25519QRTokenVerifier(publicKey)
+	verifier.nowFn = func() time.Time { return now }
+
+	token, _, err := issuer.Issue(45, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, "v2.45.") {
+		t.Fatalf("unexpected token format: %s", token)
+	}
+
+	id, err := verifier.VerifyAndExtractRecordID(token)
+	if err != nil {
+		t.Fatalf("verify error: %v", err)
+	}
+	if id != 45 {
+		t.Fatalf("expected id 45, got %d", id)
+	}
+
+	tampered := strings.Replace(token, "v2.45.", "v2.46.", 1)
+	if _, err := verifier.VerifyAndExtractRecordID(tampered); !errors.Is(err, ErrInvalidQRToken) {
+		t.Fatalf("expected ErrInvalidQRToken for tampered token, got %v", err)
+	}
+
+	verifier.nowFn = func() time.Time { return now.Add(11 * time.Minute) }
+	if _, err := verifier.VerifyAndExtractRecordID(token); !errors.Is(err, ErrExpiredQRToken) {
+		t.Fatalf("expected ErrExpiredQRToken, got %v", err)
+	}
+}
+
+func TestVersionedQRTokenVerifierDispatchesByPrefix(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := "my-qr-secret"
+	verifier := NewVersionedQRTokenVerifier(map[string]QRTokenVerifier{
+		"v1": NewCompactQRTokenVerifier(secret),
+		"v2": NewEd25519QRTokenVerifier(publicKey),
+	})
+
+	v1Token := signCompactToken(10, secret, time.Now().Add(time.Hour).Unix())
+	v2Token, _, err := NewEd25519QRTokenIssuer(privateKey, time.Hour, 0).Issue(20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, err := verifier.VerifyAndExtractRecordID(v1Token); err != nil || id != 10 {
+		t.Fatalf("expected v1 token to verify as 10, got %d, %v", id, err)
+	}
+	if id, err := verifier.VerifyAndExtractRecordID(v2Token); err != nil || id != 20 {
+		t.Fatalf("expected v2 token to verify as 20, got %d, %v", id, err)
+	}
+	if _, err := verifier.VerifyAndExtractRecordID("v9.1.2.abc"); !errors.Is(err, ErrInvalidQRToken) {
+		t.Fatalf("expected ErrInvalidQRToken for unknown version, got %v", err)
+	}
+
+	v1Only := NewVersionedQRTokenVerifier(map[string]QRTokenVerifier{"v1": NewCompactQRTokenVerifier(secret)})
+	if _, err := v1Only.VerifyAndExtractRecordID(v2Token); !errors.Is(err, ErrQRVerifierUnavailable) {
+		t.Fatalf("expected ErrQRVerifierUnavailable without v2 key, got %v", err)
+	}
+}
+
+func TestParseEd25519Keys(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromSeed, err := ParseEd25519PrivateKey(base64.StdEncoding.EncodeToString(privateKey.Seed()))
+	if err != nil || !fromSeed.Equal(privateKey) {
+		t.Fatalf("seed parse failed: %v", err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromPEM, err := ParseEd25519PrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})))
+	if err != nil || !fromPEM.Equal(privateKey) {
+		t.Fatalf("pem parse failed: %v", err)
+	}
+
+	pub, err := ParseEd25519PublicKey(base64.RawURLEncoding.EncodeToString(publicKey))
+	if err != nil || !pub.Equal(publicKey) {
+		t.Fatalf("public key parse failed: %v", err)
+	}
+
+	if _, err := ParseEd25519PrivateKey("bm90LWEta2V5"); !errors.Is(err, ErrInvalidQRSigningKey) {
+		t.Fatalf("expected ErrInvalidQRSigningKey, got %v", err)
+	}
+}
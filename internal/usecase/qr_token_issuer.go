@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrQRIssuerUnavailable = errors.New("qr issuer unavailable")
+	ErrInvalidQRTokenTTL   = errors.New("invalid qr token ttl")
+)
+
+type QRTokenIssuer interface {
+	Issue(recordID int64, ttl time.Duration) (string, time.Time, error)
+}
+
+type CompactQRTokenIssuer struct {
+	secret     []byte
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	nowFn      func() time.Time
+}
+
+func NewCompactQRTokenIssuer(secret string, defaultTTL, maxTTL time.Duration) *CompactQRTokenIssuer {
+	return &CompactQRTokenIssuer{
+		secret:     []byte(strings.TrimSpace(secret)),
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		nowFn:      time.Now,
+	}
+}
+
+// Issue signs a v1 compact token for recordID. A zero ttl falls back to the issuer default.
+func (i *CompactQRTokenIssuer) Issue(recordID int64, ttl time.Duration) (string, time.Time, error) {
+	if len(i.secret) == 0 {
+		return "", time.Time{}, ErrQRIssuerUnavailable
+	}
+	if recordID <= 0 {
+		return "", time.Time{}, ErrInvalidQRToken
+	}
+	if ttl == 0 {
+		ttl = i.defaultTTL
+	}
+	if ttl <= 0 || (i.maxTTL > 0 && ttl > i.maxTTL) {
+		return "", time.Time{}, ErrInvalidQRTokenTTL
+	}
+
+	expiresAt := i.nowFn().UTC().Add(ttl).Truncate(time.Second)
+	exp := expiresAt.Unix()
+	sig := compactTokenMAC(i.secret, "v1", recordID, exp)
+	token := fmt.Sprintf("v1.%d.%d.%s", recordID, exp, base64.RawURLEncoding.EncodeToString(sig))
+	return token, expiresAt, nil
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompactQRTokenIssuerRoundTrip(t *testing.T) {
+	secret := "my-qr-secret"
+	now := time.Unix(1700000000, 0).UTC()
+
+	issuer := NewCompactQRTokenIssuer(secret, 10*time.Minute, time.Hour)
+	issuer.nowFn = func() time.Time { return now }
+	verifier := NewCompactQRTokenVerifier(secret)
+	verifier.nowFn = func() time.Time { return now }
+
+	token, expiresAt, err := issuer.Issue(45, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != signCompactToken(45, secret, now.Add(10*time.Minute).Unix()) {
+		t.Fatalf("issued token does not match reference signer: %s", token)
+	}
+	if !expiresAt.Equal(now.Add(10 * time.Minute)) {
+		t.Fatalf("unexpected expiration: %s", expiresAt)
+	}
+
+	id, err := verifier.VerifyAndExtractRecordID(token)
+	if err != nil {
+		t.Fatalf("verify error: %v", err)
+	}
+	if id != 45 {
+		t.Fatalf("expected id 45, got %d", id)
+	}
+}
+
+func TestCompactQRTokenIssuerRejectsTTLAboveMax(t *testing.T) {
+	issuer := NewCompactQRTokenIssuer("my-qr-secret", 10*time.Minute, time.Hour)
+	_, _, err := issuer.Issue(45, 2*time.Hour)
+	if !errors.Is(err, ErrInvalidQRTokenTTL) {
+		t.Fatalf("expected ErrInvalidQRTokenTTL, got %v", err)
+	}
+}
+
+func TestCompactQRTokenIssuerWithoutSecret(t *testing.T) {
+	issuer := NewCompactQRTokenIssuer("", 10*time.Minute, time.Hour)
+	_, _, err := issuer.Issue(45, 0)
+	if !errors.Is(err, ErrQRIssuerUnavailable) {
+		t.Fatalf("expected ErrQRIssuerUnavailable, got %v", err)
+	}
+}
@@ -17,6 +17,9 @@ var (
 	ErrQRVerifierUnavailable = errors.New("qr verifier unavailable")
 )
 
+// QRTokenVerifier checks a QR token and returns the record id it was issued for. A token whose
+// signature is valid but that has expired yields ErrExpiredQRToken together with its record id,
+// so the attempt can still be attributed to the pass.
 type QRTokenVerifier interface {
 	VerifyAndExtractRecordID(token string) (int64, error)
 }
@@ -38,43 +41,109 @@ func (v *CompactQRTokenVerifier) VerifyAndExtractRecordID(token string) (int64,
 		return 0, ErrQRVerifierUnavailable
 	}
 
+	parsed, err := parseCompactToken(token, "v1")
+	if err != nil {
+		return 0, err
+	}
+
+	expected := compactTokenMAC(v.secret, parsed.version, parsed.recordID, parsed.exp)
+	if !hmac.Equal(expected, parsed.signature) {
+		return 0, ErrInvalidQRToken
+	}
+
+	if v.nowFn().UTC().Unix() > parsed.exp {
+		return parsed.recordID, ErrExpiredQRToken
+	}
+
+	return parsed.recordID, nil
+}
+
+// VersionedQRTokenVerifier dispatches a token to the verifier registered for its version prefix,
+// so several token formats can be accepted side by side during a migration.
+type VersionedQRTokenVerifier struct {
+	verifiers map[string]QRTokenVerifier
+}
+
+func NewVersionedQRTokenVerifier(verifiers map[string]QRTokenVerifier) *VersionedQRTokenVerifier {
+	cloned := make(map[string]QRTokenVerifier, len(verifiers))
+	for version, verifier := range verifiers {
+		if verifier != nil {
+			cloned[version] = verifier
+		}
+	}
+	return &VersionedQRTokenVerifier{verifiers: cloned}
+}
+
+func (v *VersionedQRTokenVerifier) VerifyAndExtractRecordID(token string) (int64, error) {
+	version, _, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return 0, ErrInvalidQRToken
+	}
+	verifier, found := v.verifiers[version]
+	if !found {
+		if knownQRTokenVersion(version) {
+			return 0, ErrQRVerifierUnavailable
+		}
+		return 0, ErrInvalidQRToken
+	}
+	return verifier.VerifyAndExtractRecordID(token)
+}
+
+func knownQRTokenVersion(version string) bool {
+	return version == "v1" || version == "v2"
+}
+
+type compactToken struct {
+	version   string
+	recordID  int64
+	exp       int64
+	signature []byte
+}
+
+// parseCompactToken splits a `<version>.<id>.<exp>.<sig>` token without checking the signature.
+func parseCompactToken(token, wantVersion string) (compactToken, error) {
 	parts := strings.Split(strings.TrimSpace(token), ".")
 	if len(parts) != 4 {
-		return 0, ErrInvalidQRToken
+		return compactToken{}, ErrInvalidQRToken
 	}
 
 	version := parts[0]
-	if version != "v1" {
-		return 0, ErrInvalidQRToken
+	if version != wantVersion {
+		return compactToken{}, ErrInvalidQRToken
 	}
 
 	recordID, err := strconv.ParseInt(parts[1], 10, 64)
 	if err != nil || recordID <= 0 {
-		return 0, ErrInvalidQRToken
+		return compactToken{}, ErrInvalidQRToken
 	}
 
 	exp, err := strconv.ParseInt(parts[2], 10, 64)
 	if err != nil || exp <= 0 {
-		return 0, ErrInvalidQRToken
+		return compactToken{}, ErrInvalidQRToken
 	}
 
-	body := fmt.Sprintf("%s|%d|%d", version, recordID, exp)
-	mac := hmac.New(sha256.New, v.secret)
-	_, _ = mac.Write([]byte(body))
-	expected := mac.Sum(nil)[:16]
-	provided, err := decodeBase64URL(parts[3])
+	signature, err := decodeBase64URL(parts[3])
 	if err != nil {
-		return 0, ErrInvalidQRToken
-	}
-	if !hmac.Equal(expected, provided) {
-		return 0, ErrInvalidQRToken
+		return compactToken{}, ErrInvalidQRToken
 	}
 
-	if v.nowFn().UTC().Unix() > exp {
-		return 0, ErrExpiredQRToken
-	}
+	return compactToken{version: version, recordID: recordID, exp: exp, signature: signature}, nil
+}
+
+// compactTokenMAC returns the truncated HMAC-SHA256 shared by the compact token issuer and verifier.
+func compactTokenMAC(secret []byte, version string, recordID, exp int64) []byte {
+	return truncatedHMAC(secret, compactTokenBody(version, recordID, exp))
+}
+
+func truncatedHMAC(secret, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return mac.Sum(nil)[:16]
+}
 
-	return recordID, nil
+// compactTokenBody is the byte string covered by the signature of every compact token version.
+func compactTokenBody(version string, recordID, exp int64) []byte {
+	return []byte(fmt.Sprintf("%s|%d|%d", version, recordID, exp))
 }
 
 func decodeBase64URL(raw string) ([]byte, error) {
@@ -39,10 +39,18 @@ func TestCompactQRTokenVerifierExpired(t *testing.T) {
 	exp := time.Unix(1700000600, 0).UTC().Unix()
 	token := signCompactToken(45, secret, exp)
 
-	_, err := v.VerifyAndExtractRecordID(token)
+	id, err := v.VerifyAndExtractRecordID(token)
 	if !errors.Is(err, ErrExpiredQRToken) {
 		t.Fatalf("expected ErrExpiredQRToken, got %v", err)
 	}
+	if id != 45 {
+		t.Fatalf("expected the record id of the expired token, got %d", id)
+	}
+
+	forged := token[:len(token)-2] + "AA"
+	if id, err := v.VerifyAndExtractRecordID(forged); !errors.Is(err, ErrInvalidQRToken) || id != 0 {
+		t.Fatalf("expected ErrInvalidQRToken without id, got %d, %v", id, err)
+	}
 }
 
 func TestCompactQRTokenVerifierInvalidSignature(t *testing.T) {
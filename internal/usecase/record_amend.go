@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// Amend applies in to the record when its current version is expectedVersion. Derived fields
+// (Contenedor, LibreRetencionHasta, TituloTerminal) are recomputed with the rules used by Create
+// when their inputs change, and the changed fields are appended to the version history. An amendment that changes
+// nothing returns the stored record without creating a new version.
+func (s *RecordService) Amend(ctx context.Context, recordID int64, expectedVersion int, in domain.AmendRecordInput) (domain.Record, error) {
+	if strings.TrimSpace(in.ChangedBy) == "" {
+		return domain.Record{}, domain.ErrUnauthorized
+	}
+	if recordID <= 0 || expectedVersion <= 0 {
+		return domain.Record{}, domain.ErrInvalidInput
+	}
+
+	current, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	if current.Version != expectedVersion {
+		return domain.Record{}, domain.ErrPreconditionFailed
+	}
+	if current.Status == domain.StatusRevoked || current.Status == domain.StatusSuperseded {
+		return domain.Record{}, domain.ErrConflict
+	}
+
+	next, err := applyAmendment(current, in)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	// The port code and title are resolved again only for a new port, so catalogue edits do not
+	// silently change passes already issued.
+	if next.PuertoDescargue != current.PuertoDescargue {
+		s.assignPort(&next)
+		if err := s.assignTerminal(ctx, &next); err != nil {
+			return domain.Record{}, err
+		}
+	} else {
+		s.fillTituloTerminal(ctx, &next)
+	}
+	// Free time follows the same rule: a holiday added after issue must not move the deadline
+	// of a pass whose fecha_real and dias_libre are untouched.
+	if next.BaseFechaReal().Equal(current.BaseFechaReal()) && next.DiasLibre == current.DiasLibre {
+		next.LibreRetencionHasta = current.LibreRetencionHasta
+	} else if err := s.applyFreeTime(ctx, &next, next.BaseFechaReal()); err != nil {
+		return domain.Record{}, err
+	}
+	changes := diffRecords(current, next)
+	if len(changes) == 0 {
+		return current, nil
+	}
+
+	next.Version = expectedVersion + 1
+	err = s.repo.Amend(ctx, next, expectedVersion, domain.RecordVersion{
+		RecordID:  recordID,
+		Version:   next.Version,
+		ChangedBy: strings.TrimSpace(in.ChangedBy),
+		ChangedAt: s.nowFn().UTC(),
+		Changes:   changes,
+	})
+	if err != nil {
+		return domain.Record{}, err
+	}
+	return next, nil
+}
+
+// ListVersions returns the amendment history of a record, oldest first.
+func (s *RecordService) ListVersions(ctx context.Context, recordID int64) ([]domain.RecordVersion, error) {
+	if recordID <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+	if _, err := s.repo.FindByID(ctx, recordID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(ctx, recordID)
+}
+
+func applyAmendment(rec domain.Record, in domain.AmendRecordInput) (domain.Record, error) {
+	for _, required := range []*string{in.Nave, in.Viaje, in.Cliente, in.Booking, in.PuertoDescargue} {
+		if required != nil && strings.TrimSpace(*required) == "" {
+			return domain.Record{}, domain.ErrInvalidInput
+		}
+	}
+	if in.DiasLibre != nil && *in.DiasLibre < 0 {
+		return domain.Record{}, domain.ErrInvalidInput
+	}
+
+	setTrimmed(&rec.Nave, in.Nave)
+	setTrimmed(&rec.Viaje, in.Viaje)
+	setTrimmed(&rec.Cliente, in.Cliente)
+	setTrimmed(&rec.Booking, in.Booking)
+	setTrimmed(&rec.PuertoDescargue, in.PuertoDescargue)
+
+	// Rebuild the inputs Create received so the container rules run again on the merged values.
+	// Untouched container fields are kept as stored, so records issued before a rule existed
+	// remain amendable.
+	ramaChanged := in.Rama != nil && !strings.EqualFold(strings.TrimSpace(*in.Rama), rec.Rama)
+	if ramaChanged || in.Containers != nil || in.ContenedorSerie != nil || in.CodigoISO != nil || in.Transportista != nil {
+		rama, lines, transportista := rec.Rama, recordContainers(rec), rec.Transportista
+		if ramaChanged {
+			rama, lines, transportista = *in.Rama, nil, ""
+		}
+		if in.Containers != nil {
+			lines = *in.Containers
+		} else if in.ContenedorSerie != nil || in.CodigoISO != nil {
+			if len(lines) > 1 {
+				return domain.Record{}, fmt.Errorf("%w: use contenedores to amend a pass with several containers", domain.ErrInvalidInput)
+			}
+			line := domain.ContainerLine{}
+			if len(lines) == 1 {
+				line = lines[0]
+			}
+			setTrimmed(&line.Serie, in.ContenedorSerie)
+			setTrimmed(&line.CodigoISO, in.CodigoISO)
+			lines = []domain.ContainerLine{line}
+		}
+		setTrimmed(&transportista, in.Transportista)
+
+		var err error
+		rec.Rama, rec.Containers, rec.Contenedor, rec.Transportista, err = resolveContenedorData(rama, lines, transportista)
+		if err != nil {
+			return domain.Record{}, fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
+		}
+	}
+
+	if len(rec.Containers) == 0 {
+		// The repository rewrites the lines on every amendment; never let it drop them.
+		rec.Containers = recordContainers(rec)
+	}
+
+	// A legacy pass keeps fecha_real empty until the operator enters one.
+	fechaReal := rec.BaseFechaReal()
+	if in.FechaReal != nil {
+		rec.FechaReal = *in.FechaReal
+		fechaReal = rec.FechaReal
+	}
+	if in.DiasLibre != nil {
+		rec.DiasLibre = *in.DiasLibre
+	}
+	rec.LibreRetencionHasta = freeTimeUntil(fechaReal, rec.DiasLibre)
+	return rec, nil
+}
+
+// formatDate prints a calendar date, or nothing for the empty fecha_real of a legacy pass.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func setTrimmed(dst *string, value *string) {
+	if value != nil {
+		*dst = strings.TrimSpace(*value)
+	}
+}
+
+func diffRecords(before, after domain.Record) []domain.FieldChange {
+	var changes []domain.FieldChange
+	add := func(field, b, a string) {
+		if b != a {
+			changes = append(changes, domain.FieldChange{Field: field, Before: b, After: a})
+		}
+	}
+	add("nave", before.Nave, after.Nave)
+	add("viaje", before.Viaje, after.Viaje)
+	add("cliente", before.Cliente, after.Cliente)
+	add("booking", before.Booking, after.Booking)
+	add("rama", before.Rama, after.Rama)
+	add("contenedor", before.Contenedor, after.Contenedor)
+	add("puerto_descargue", before.PuertoDescargue, after.PuertoDescargue)
+	add("puerto_locode", before.PuertoLocode, after.PuertoLocode)
+	add("puerto_nombre", before.PuertoNombre, after.PuertoNombre)
+	add("fecha_real", formatDate(before.FechaReal), formatDate(after.FechaReal))
+	add("libre_retencion_hasta", before.LibreRetencionHasta.Format("2006-01-02"), after.LibreRetencionHasta.Format("2006-01-02"))
+	add("dias_libre", strconv.Itoa(before.DiasLibre), strconv.Itoa(after.DiasLibre))
+	add("transportista", before.Transportista, after.Transportista)
+	add("titulo_terminal", before.TituloTerminal, after.TituloTerminal)
+	return changes
+}
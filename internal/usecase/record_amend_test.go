@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func amendableRecord() domain.Record {
+	return domain.Record{
+		ID:                  10,
+		Nave:                "NYK DENEP",
+		Viaje:               "072E",
+		Cliente:             "CAPITAL PACIFICO, S.A.",
+		Booking:             "YMLUL160382911",
+		Rama:                "internacional",
+		Contenedor:          "YMLU5374938",
+		PuertoDescargue:     "BALBOA",
+		FechaReal:           time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+		LibreRetencionHasta: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC),
+		DiasLibre:           3,
+		TituloTerminal:      "TERMINAL PACIFICO - BALBOA",
+		Status:              domain.StatusIssued,
+		Version:             2,
+	}
+}
+
+func TestAmendRecomputesDerivedFieldsAndRecordsHistory(t *testing.T) {
+	var stored domain.Record
+	var history domain.RecordVersion
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+		amendFn: func(_ context.Context, r domain.Record, expectedVersion int, change domain.RecordVersion) error {
+			if expectedVersion != 2 {
+				t.Fatalf("unexpected expected version %d", expectedVersion)
+			}
+			stored, history = r, change
+			return nil
+		},
+	})
+
+	nave, puerto, dias := "NYK DENEB", "CRISTOBAL", 5
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{
+		Nave:            &nave,
+		PuertoDescargue: &puerto,
+		DiasLibre:       &dias,
+		ChangedBy:       "user-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Version != 3 || stored.Version != 3 || history.Version != 3 {
+		t.Fatalf("expected version 3, got record %d, stored %d, history %d", rec.Version, stored.Version, history.Version)
+	}
+	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-14" {
+		t.Fatalf("expected libre_retencion_hasta recomputed from fecha_real 2026-02-09, got %s", got)
+	}
+	if rec.TituloTerminal != "TERMINAL ATLANTICO - CRISTOBAL" {
+		t.Fatalf("unexpected titulo_terminal: %s", rec.TituloTerminal)
+	}
+
+	fields := map[string]domain.FieldChange{}
+	for _, c := range history.Changes {
+		fields[c.Field] = c
+	}
+	if len(fields) != 5 || fields["nave"].Before != "NYK DENEP" || fields["dias_libre"].After != "5" {
+		t.Fatalf("unexpected changes: %+v", history.Changes)
+	}
+	if history.ChangedBy != "user-1" || history.ChangedAt.IsZero() {
+		t.Fatalf("unexpected history author: %+v", history)
+	}
+}
+
+func TestAmendLegacyPassKeepsFechaRealEmpty(t *testing.T) {
+	legacy := amendableRecord()
+	legacy.FechaReal = time.Time{}
+	var history domain.RecordVersion
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return legacy, nil },
+		amendFn: func(_ context.Context, _ domain.Record, _ int, change domain.RecordVersion) error {
+			history = change
+			return nil
+		},
+	})
+
+	dias := 5
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{DiasLibre: &dias, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.FechaReal.IsZero() || rec.LibreRetencionHasta.Format("2006-01-02") != "2026-02-14" {
+		t.Fatalf("expected deadline counted from the derived 2026-02-09, got %+v", rec)
+	}
+	for _, c := range history.Changes {
+		if c.Field == "fecha_real" {
+			t.Fatalf("fecha_real was not entered and must not appear in the history: %+v", c)
+		}
+	}
+
+	fechaReal := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{FechaReal: &fechaReal, ChangedBy: "user-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history.Changes) != 2 || history.Changes[0].Field != "fecha_real" || history.Changes[0].Before != "" || history.Changes[0].After != "2026-02-10" {
+		t.Fatalf("expected the entered fecha_real in the history, got %+v", history.Changes)
+	}
+}
+
+func TestAmendSwitchesRamaThroughCreateRules(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+	})
+
+	rama := "nacional"
+	if _, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{Rama: &rama, ChangedBy: "user-1"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without codigo_iso and transportista, got %v", err)
+	}
+
+	iso, trans := "45g1", "TRANSPORTES ABC"
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{Rama: &rama, CodigoISO: &iso, Transportista: &trans, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Contenedor != "1 X 45G1" || rec.Transportista != "TRANSPORTES ABC" {
+		t.Fatalf("unexpected container data: %s / %s", rec.Contenedor, rec.Transportista)
+	}
+}
+
+func TestAmendRejectsStaleVersion(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+		amendFn: func(context.Context, domain.Record, int, domain.RecordVersion) error {
+			t.Fatal("amend must not reach the repository")
+			return nil
+		},
+	})
+
+	nave := "OTRA"
+	_, err := svc.Amend(context.Background(), 10, 1, domain.AmendRecordInput{Nave: &nave, ChangedBy: "user-1"})
+	if !errors.Is(err, domain.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestAmendWithoutChangesKeepsVersion(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+		amendFn: func(context.Context, domain.Record, int, domain.RecordVersion) error {
+			t.Fatal("no-op amendment must not create a version")
+			return nil
+		},
+	})
+
+	nave := " NYK DENEP "
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{Nave: &nave, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Version != 2 {
+		t.Fatalf("expected version 2, got %d", rec.Version)
+	}
+}
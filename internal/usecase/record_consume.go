@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// ErrPassNotConsumable is returned when a pass is presented for consumption but is not an
+// issued pass with uses left. It wraps domain.ErrConflict.
+var ErrPassNotConsumable = fmt.Errorf("%w: pass cannot be consumed", domain.ErrConflict)
+
+// Consume validates a token presented by a gate device and atomically counts one use of the
+// pass. The returned validation reflects the state after the attempt, and the attempt is
+// appended to the scan log like a read-only validation.
+func (s *RecordService) Consume(ctx context.Context, token, scanner, clientIP string) (QRValidation, error) {
+	result, err := s.ValidateQRToken(ctx, token)
+	if err == nil {
+		result, err = s.consume(ctx, result)
+	}
+	if logErr := s.logScan(ctx, result, err, scanner, clientIP); logErr != nil {
+		return QRValidation{}, errors.Join(err, logErr)
+	}
+	return result, err
+}
+
+func (s *RecordService) consume(ctx context.Context, result QRValidation) (QRValidation, error) {
+	if !result.Valid() {
+		return result, ErrPassNotConsumable
+	}
+
+	err := s.repo.ConsumeUse(ctx, result.Record.ID, s.nowFn().UTC())
+	if err != nil && !errors.Is(err, domain.ErrConflict) {
+		return QRValidation{}, err
+	}
+
+	rec, findErr := s.repo.FindByID(ctx, result.Record.ID)
+	if findErr != nil {
+		return QRValidation{}, findErr
+	}
+	result.Record = rec
+	if err != nil {
+		// Another gate consumed the last use concurrently.
+		result.Status = rec.EffectiveStatus(s.nowFn().In(s.Location(ctx, rec)))
+		return result, ErrPassNotConsumable
+	}
+	// The use just counted is accepted even if it exhausted the pass.
+	result.Status = domain.StatusIssued
+	return result, nil
+}
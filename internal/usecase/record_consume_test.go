@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func TestConsumeCountsUsesUntilExhausted(t *testing.T) {
+	rec := domain.Record{ID: 7, Status: domain.StatusIssued, MaxUses: 1}
+	scans := &mockScans{}
+	svc := NewRecordService(
+		mockRepo{
+			findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return rec, nil },
+			consumeUseFn: func(_ context.Context, _ int64) error {
+				if rec.Status != domain.StatusIssued || rec.UseCount >= rec.MaxUses {
+					return domain.ErrConflict
+				}
+				rec.UseCount++
+				if rec.UseCount >= rec.MaxUses {
+					rec.Status = domain.StatusUsed
+				}
+				return nil
+			},
+		},
+		mockVerifier{verifyFn: func(string) (int64, error) { return 7, nil }},
+	).WithScanLog(scans)
+
+	first, err := svc.Consume(context.Background(), "tok", "gate-1", "10.0.0.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !first.Valid() || first.Record.UseCount != 1 || first.Record.Status != domain.StatusUsed {
+		t.Fatalf("unexpected first consume result: %+v", first)
+	}
+
+	second, err := svc.Consume(context.Background(), "tok", "gate-1", "10.0.0.5")
+	if !errors.Is(err, ErrPassNotConsumable) || !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrPassNotConsumable, got %v", err)
+	}
+	if second.Status != domain.StatusUsed {
+		t.Fatalf("expected status used, got %s", second.Status)
+	}
+
+	if len(scans.inserted) != 2 || scans.inserted[0].Outcome != domain.ScanValid || scans.inserted[1].Outcome != domain.ScanUsed {
+		t.Fatalf("unexpected scan log: %+v", scans.inserted)
+	}
+}
+
+func TestConsumeLosesRace(t *testing.T) {
+	svc := NewRecordService(
+		mockRepo{
+			findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+				return domain.Record{ID: id, Status: domain.StatusIssued, MaxUses: 2, UseCount: 2}, nil
+			},
+			consumeUseFn: func(_ context.Context, _ int64) error { return domain.ErrConflict },
+		},
+		mockVerifier{verifyFn: func(string) (int64, error) { return 7, nil }},
+	)
+
+	result, err := svc.Consume(context.Background(), "tok", "gate-1", "")
+	if !errors.Is(err, ErrPassNotConsumable) {
+		t.Fatalf("expected ErrPassNotConsumable, got %v", err)
+	}
+	if result.Record.UseCount != 2 {
+		t.Fatalf("expected reloaded record, got %+v", result.Record)
+	}
+}
+
+func TestCreateRejectsNonPositiveMaxUses(t *testing.T) {
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) { return 1, nil }})
+	dias, zero := 3, 0
+	_, _, err := svc.Create(context.Background(), domain.CreateRecordInput{
+		Nave:            "NAVE TEST",
+		Viaje:           "VJ001",
+		Cliente:         "CLIENTE TEST",
+		Booking:         "BK001",
+		Rama:            "internacional",
+		ContenedorSerie: "ABCU1234560",
+		FechaReal:       time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+		DiasLibre:       &dias,
+		PuertoDescargue: "Balboa",
+		UsuarioFirma:    "user-1",
+		MaxUses:         &zero,
+	})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// maxContainersPerPass bounds the containers a single truck pass may carry.
+const maxContainersPerPass = 4
+
+// nationalContainerPrefix precedes the ISO size/type code in the contenedor of single-box
+// nacional records.
+const nationalContainerPrefix = "1 X "
+
+// resolveContenedorData applies the container rules to the lines of a pass and returns the
+// rama (inferred when empty), the normalized lines, the contenedor display string and the
+// transportista to store. Internacional lines carry one ISO 6346 serial each; nacional lines
+// carry a catalogued size/type code with a quantity and require a transportista.
+func resolveContenedorData(rama string, lines []domain.ContainerLine, transportista string) (string, []domain.ContainerLine, string, string, error) {
+	normalizedRama := strings.ToLower(strings.TrimSpace(rama))
+	if normalizedRama == "" {
+		for _, line := range lines {
+			if strings.TrimSpace(line.Serie) != "" {
+				normalizedRama = "internacional"
+				break
+			}
+		}
+	}
+	if normalizedRama == "" && (len(lines) > 0 || strings.TrimSpace(transportista) != "") {
+		normalizedRama = "nacional"
+	}
+
+	switch normalizedRama {
+	case "internacional":
+		if len(lines) == 0 {
+			return "", nil, "", "", errors.New("contenedor_serie is required for internacional")
+		}
+		if len(lines) > maxContainersPerPass {
+			return "", nil, "", "", fmt.Errorf("a pass carries at most %d containers", maxContainersPerPass)
+		}
+		out := make([]domain.ContainerLine, 0, len(lines))
+		seen := make(map[string]bool, len(lines))
+		series := make([]string, 0, len(lines))
+		for _, line := range lines {
+			serie := strings.TrimSpace(line.Serie)
+			if serie == "" {
+				return "", nil, "", "", errors.New("contenedor_serie is required for internacional")
+			}
+			if line.Cantidad > 1 {
+				return "", nil, "", "", errors.New("cantidad must be 1 for internacional containers")
+			}
+			cn, err := domain.ParseContainerNumber(serie)
+			if err != nil {
+				return "", nil, "", "", err
+			}
+			if seen[cn.String()] {
+				return "", nil, "", "", fmt.Errorf("container %s is listed twice", cn)
+			}
+			seen[cn.String()] = true
+			out = append(out, domain.ContainerLine{Serie: cn.String(), Cantidad: 1})
+			series = append(series, cn.String())
+		}
+		return "internacional", out, strings.Join(series, " / "), "", nil
+	case "nacional":
+		trans := strings.TrimSpace(transportista)
+		if len(lines) == 0 || trans == "" {
+			return "", nil, "", "", errors.New("codigo_iso and transportista are required for nacional")
+		}
+		out := make([]domain.ContainerLine, 0, len(lines))
+		seen := make(map[string]bool, len(lines))
+		parts := make([]string, 0, len(lines))
+		total := 0
+		for _, line := range lines {
+			iso := strings.TrimSpace(line.CodigoISO)
+			if iso == "" {
+				return "", nil, "", "", errors.New("codigo_iso and transportista are required for nacional")
+			}
+			ct, ok := domain.LookupContainerType(iso)
+			if !ok {
+				return "", nil, "", "", fmt.Errorf("%w: codigo_iso %q", domain.ErrUnknownContainerType, iso)
+			}
+			if seen[ct.Code] {
+				return "", nil, "", "", fmt.Errorf("codigo_iso %s is listed twice; use cantidad", ct.Code)
+			}
+			seen[ct.Code] = true
+			cantidad := line.Cantidad
+			if cantidad == 0 {
+				cantidad = 1
+			}
+			if cantidad < 0 {
+				return "", nil, "", "", errors.New("cantidad must be positive")
+			}
+			total += cantidad
+			out = append(out, domain.ContainerLine{CodigoISO: ct.Code, Cantidad: cantidad})
+			parts = append(parts, strconv.Itoa(cantidad)+" X "+ct.Code)
+		}
+		if total > maxContainersPerPass {
+			return "", nil, "", "", fmt.Errorf("a pass carries at most %d containers", maxContainersPerPass)
+		}
+		return "nacional", out, strings.Join(parts, " + "), trans, nil
+	default:
+		return "", nil, "", "", errors.New("rama could not be inferred; provide contenedor_serie or codigo_iso+transportista")
+	}
+}
+
+// recordContainers returns the container lines of rec, rebuilding them from the contenedor
+// display string when rec was loaded without its lines.
+func recordContainers(rec domain.Record) []domain.ContainerLine {
+	if len(rec.Containers) > 0 {
+		return rec.Containers
+	}
+	if rec.Rama != "nacional" {
+		var lines []domain.ContainerLine
+		for _, serie := range strings.Split(rec.Contenedor, " / ") {
+			lines = append(lines, domain.ContainerLine{Serie: serie, Cantidad: 1})
+		}
+		return lines
+	}
+	var lines []domain.ContainerLine
+	for _, part := range strings.Split(rec.Contenedor, " + ") {
+		qty, code, found := strings.Cut(part, " X ")
+		n, err := strconv.Atoi(qty)
+		if !found || err != nil {
+			return []domain.ContainerLine{{CodigoISO: strings.TrimPrefix(rec.Contenedor, nationalContainerPrefix), Cantidad: 1}}
+		}
+		lines = append(lines, domain.ContainerLine{CodigoISO: code, Cantidad: n})
+	}
+	return lines
+}
+
+// ContainerDescription renders the contenedor of rec for people: nacional lines with a
+// catalogued size/type code read "1 X 40' HIGH CUBE" (joined with " + "); anything else is
+// returned as stored.
+func ContainerDescription(rec domain.Record) string {
+	if rec.Rama != "nacional" {
+		return rec.Contenedor
+	}
+	lines := recordContainers(rec)
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		ct, ok := domain.LookupContainerType(line.CodigoISO)
+		if !ok {
+			return rec.Contenedor
+		}
+		parts = append(parts, strconv.Itoa(line.Cantidad)+" X "+ct.Description)
+	}
+	return strings.Join(parts, " + ")
+}
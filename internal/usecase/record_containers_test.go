@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func multiContainerInput() domain.CreateRecordInput {
+	return domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001",
+		FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), PuertoDescargue: "Balboa", UsuarioFirma: "user-1",
+	}
+}
+
+func TestCreateWithSeveralInternacionalContainers(t *testing.T) {
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+		stored = r
+		return 1, nil
+	}})
+
+	in := multiContainerInput()
+	in.Containers = []domain.ContainerLine{{Serie: "ymlu 537493-8"}, {Serie: "ABCU1234560"}}
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Rama != "internacional" || rec.Contenedor != "YMLU5374938 / ABCU1234560" {
+		t.Fatalf("unexpected container data: %s / %s", rec.Rama, rec.Contenedor)
+	}
+	if len(stored.Containers) != 2 || stored.Containers[0] != (domain.ContainerLine{Serie: "YMLU5374938", Cantidad: 1}) {
+		t.Fatalf("unexpected stored lines: %+v", stored.Containers)
+	}
+
+	in.Containers = []domain.ContainerLine{{Serie: "YMLU5374938"}, {Serie: "YMLU 5374938"}}
+	if _, _, err := svc.Create(context.Background(), in); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected duplicate container to be rejected, got %v", err)
+	}
+}
+
+func TestCreateWithNacionalQuantities(t *testing.T) {
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) { return 1, nil }})
+
+	in := multiContainerInput()
+	in.Transportista = "TRANSPORTE SA"
+	in.Containers = []domain.ContainerLine{{CodigoISO: "22g1", Cantidad: 2}, {CodigoISO: "45G1"}}
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Rama != "nacional" || rec.Contenedor != "2 X 22G1 + 1 X 45G1" {
+		t.Fatalf("unexpected container data: %s / %s", rec.Rama, rec.Contenedor)
+	}
+	if got := ContainerDescription(rec); got != "2 X 20' DRY + 1 X 40' HIGH CUBE" {
+		t.Fatalf("unexpected description %q", got)
+	}
+
+	in.Containers = []domain.ContainerLine{{CodigoISO: "22G1", Cantidad: 3}, {CodigoISO: "45G1", Cantidad: 2}}
+	if _, _, err := svc.Create(context.Background(), in); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected more than %d containers to be rejected, got %v", maxContainersPerPass, err)
+	}
+}
+
+func TestContainerDescriptionRebuildsLinesFromContenedor(t *testing.T) {
+	rec := domain.Record{Rama: "nacional", Contenedor: "2 X 22G1 + 1 X 45G1"}
+	if got := ContainerDescription(rec); got != "2 X 20' DRY + 1 X 40' HIGH CUBE" {
+		t.Fatalf("unexpected description %q", got)
+	}
+	rec.Contenedor = "40HC"
+	if got := ContainerDescription(rec); got != "40HC" {
+		t.Fatalf("expected legacy value as stored, got %q", got)
+	}
+}
+
+func TestAmendReplacesContainerLines(t *testing.T) {
+	current := amendableRecord()
+	current.Containers = []domain.ContainerLine{{Serie: "YMLU5374938", Cantidad: 1}}
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return current, nil },
+		amendFn: func(_ context.Context, r domain.Record, _ int, _ domain.RecordVersion) error {
+			stored = r
+			return nil
+		},
+	})
+
+	lines := []domain.ContainerLine{{Serie: "YMLU5374938"}, {Serie: "ABCU1234560"}}
+	rec, err := svc.Amend(context.Background(), 10, 2, domain.AmendRecordInput{Containers: &lines, ChangedBy: "user-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Contenedor != "YMLU5374938 / ABCU1234560" || len(stored.Containers) != 2 {
+		t.Fatalf("unexpected containers: %s %+v", rec.Contenedor, stored.Containers)
+	}
+
+	current = stored
+	serie := "MSCU1234566"
+	if _, err := svc.Amend(context.Background(), 10, 3, domain.AmendRecordInput{ContenedorSerie: &serie, ChangedBy: "user-1"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected single-container amendment of a multi-container pass to be rejected, got %v", err)
+	}
+}
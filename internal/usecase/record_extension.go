@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+var ErrExtensionsUnavailable = errors.New("extensions unavailable")
+
+const maxExtensionDays = 365
+
+// WithExtensions enables granting extra free days on issued passes.
+func (s *RecordService) WithExtensions(repo domain.ExtensionRepository) *RecordService {
+	s.extensions = repo
+	return s
+}
+
+// Extend adds days of free time to an issued pass. LibreRetencionHasta is recomputed from the
+// original fecha_real, so validation reports the new deadline without reissuing the QR. The
+// record change, its version row and the extension are written in one transaction.
+func (s *RecordService) Extend(ctx context.Context, recordID int64, days int, reason, approvedBy, requestedBy string) (domain.Extension, domain.Record, error) {
+	if s.extensions == nil {
+		return domain.Extension{}, domain.Record{}, ErrExtensionsUnavailable
+	}
+	if strings.TrimSpace(requestedBy) == "" {
+		return domain.Extension{}, domain.Record{}, domain.ErrUnauthorized
+	}
+	if recordID <= 0 || days <= 0 || days > maxExtensionDays ||
+		strings.TrimSpace(reason) == "" || strings.TrimSpace(approvedBy) == "" {
+		return domain.Extension{}, domain.Record{}, domain.ErrInvalidInput
+	}
+
+	rec, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Extension{}, domain.Record{}, err
+	}
+	if rec.Status != "" && rec.Status != domain.StatusIssued {
+		return domain.Extension{}, domain.Record{}, fmt.Errorf("%w: record is %s", domain.ErrConflict, rec.Status)
+	}
+
+	next := rec
+	next.DiasLibre = rec.DiasLibre + days
+	if err := s.applyFreeTime(ctx, &next, rec.BaseFechaReal()); err != nil {
+		return domain.Extension{}, domain.Record{}, err
+	}
+	next.Version = rec.Version + 1
+
+	now := s.nowFn().UTC()
+	ext := domain.Extension{
+		RecordID:      recordID,
+		Days:          days,
+		Reason:        strings.TrimSpace(reason),
+		ApprovedBy:    strings.TrimSpace(approvedBy),
+		RequestedBy:   strings.TrimSpace(requestedBy),
+		PreviousUntil: rec.LibreRetencionHasta,
+		NewUntil:      next.LibreRetencionHasta,
+		CreatedAt:     now,
+	}
+	ext.ID, err = s.extensions.Extend(ctx, next, rec.Version, domain.RecordVersion{
+		RecordID:  recordID,
+		Version:   next.Version,
+		ChangedBy: strings.TrimSpace(requestedBy),
+		ChangedAt: now,
+		Changes: []domain.FieldChange{
+			{Field: "dias_libre", Before: strconv.Itoa(rec.DiasLibre), After: strconv.Itoa(next.DiasLibre)},
+			{Field: "libre_retencion_hasta", Before: rec.LibreRetencionHasta.Format("2006-01-02"), After: next.LibreRetencionHasta.Format("2006-01-02")},
+		},
+	}, ext)
+	if err != nil {
+		return domain.Extension{}, domain.Record{}, err
+	}
+	return ext, next, nil
+}
+
+// ListExtensions returns the free-time extensions of a record, oldest first.
+func (s *RecordService) ListExtensions(ctx context.Context, recordID int64) ([]domain.Extension, error) {
+	if s.extensions == nil {
+		return nil, ErrExtensionsUnavailable
+	}
+	if recordID <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+	if _, err := s.repo.FindByID(ctx, recordID); err != nil {
+		return nil, err
+	}
+	return s.extensions.ListByRecordID(ctx, recordID)
+}
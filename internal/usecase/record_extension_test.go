@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type mockExtensions struct {
+	inserted []domain.Extension
+	amended  []domain.Record
+	history  []domain.RecordVersion
+	versions []int
+	err      error
+}
+
+func (m *mockExtensions) Extend(_ context.Context, r domain.Record, expectedVersion int, change domain.RecordVersion, ext domain.Extension) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.amended = append(m.amended, r)
+	m.history = append(m.history, change)
+	m.versions = append(m.versions, expectedVersion)
+	m.inserted = append(m.inserted, ext)
+	return int64(len(m.inserted)), nil
+}
+
+func (m *mockExtensions) ListByRecordID(_ context.Context, _ int64) ([]domain.Extension, error) {
+	return m.inserted, nil
+}
+
+func TestExtendRecomputesFromFechaReal(t *testing.T) {
+	exts := &mockExtensions{}
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+	}).WithExtensions(exts)
+
+	ext, rec, err := svc.Extend(context.Background(), 10, 4, " negociado ", "gerente", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exts.amended) != 1 || exts.versions[0] != 2 {
+		t.Fatalf("expected one amendment against version 2, got %v", exts.versions)
+	}
+	amended, history := exts.amended[0], exts.history[0]
+	if rec.DiasLibre != 7 || amended.DiasLibre != 7 {
+		t.Fatalf("expected dias_libre 7, got %d", rec.DiasLibre)
+	}
+	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-16" {
+		t.Fatalf("expected 2026-02-16, got %s", got)
+	}
+	if ext.ID != 1 || ext.Reason != "negociado" || ext.ApprovedBy != "gerente" || ext.PreviousUntil.Format("2006-01-02") != "2026-02-12" {
+		t.Fatalf("unexpected extension: %+v", ext)
+	}
+	if history.Version != 3 || len(history.Changes) != 2 {
+		t.Fatalf("unexpected history entry: %+v", history)
+	}
+}
+
+func TestExtendLegacyPassCountsFromDerivedFechaReal(t *testing.T) {
+	exts := &mockExtensions{}
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) {
+			rec := amendableRecord()
+			rec.FechaReal = time.Time{}
+			return rec, nil
+		},
+	}).WithExtensions(exts)
+
+	_, rec, err := svc.Extend(context.Background(), 10, 4, "negociado", "gerente", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-16" || !exts.amended[0].FechaReal.IsZero() {
+		t.Fatalf("expected 2026-02-16 with fecha_real left empty, got %s / %s", got, exts.amended[0].FechaReal)
+	}
+}
+
+func TestExtendRejectsNonIssuedPass(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			rec := amendableRecord()
+			rec.Status = domain.StatusRevoked
+			return rec, nil
+		},
+	}).WithExtensions(&mockExtensions{})
+
+	if _, _, err := svc.Extend(context.Background(), 10, 2, "motivo", "gerente", "user-1"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if _, _, err := svc.Extend(context.Background(), 10, 0, "motivo", "gerente", "user-1"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestExtendFailedInsertLeavesRecordUnchanged(t *testing.T) {
+	insertErr := errors.New("record_extensions unavailable")
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) { return amendableRecord(), nil },
+		amendFn: func(context.Context, domain.Record, int, domain.RecordVersion) error {
+			t.Fatal("the record must not be amended outside the extension transaction")
+			return nil
+		},
+	}).WithExtensions(&mockExtensions{err: insertErr})
+
+	if _, _, err := svc.Extend(context.Background(), 10, 4, "negociado", "gerente", "user-1"); !errors.Is(err, insertErr) {
+		t.Fatalf("expected the insert error, got %v", err)
+	}
+}
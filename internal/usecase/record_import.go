@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+const (
+	importBatchSize = 100
+	maxImportRows   = 5000
+)
+
+// ImportOutcome classifies one row of a bulk import.
+type ImportOutcome string
+
+const (
+	ImportCreated  ImportOutcome = "created"
+	ImportConflict ImportOutcome = "conflict"
+	ImportInvalid  ImportOutcome = "invalid"
+)
+
+// ImportRow is one manifest row to import. Err carries a parsing failure detected before the
+// row reached the service; such rows are reported as invalid without being validated.
+type ImportRow struct {
+	Line  int
+	Input domain.CreateRecordInput
+	Err   error
+}
+
+// ImportResult reports what happened to one manifest row.
+type ImportResult struct {
+	Line     int
+	Outcome  ImportOutcome
+	RecordID int64
+	Error    string
+}
+
+// ImportRecords validates every row with the same rules as Create and inserts the valid ones in
+// transactions of importBatchSize rows. Results follow the order of rows. An infrastructure
+// failure aborts the import; batches committed before it are kept and will be reported as
+// conflicts if the same file is imported again.
+func (s *RecordService) ImportRecords(ctx context.Context, rows []ImportRow) ([]ImportResult, error) {
+	if len(rows) == 0 || len(rows) > maxImportRows {
+		return nil, domain.ErrInvalidInput
+	}
+
+	results := make([]ImportResult, len(rows))
+	pending := make([]int, 0, importBatchSize)
+	batch := make([]domain.Record, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		inserted, err := s.repo.InsertBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if len(inserted) != len(batch) {
+			return fmt.Errorf("insert batch returned %d results for %d records", len(inserted), len(batch))
+		}
+		for i, res := range inserted {
+			idx := pending[i]
+			switch {
+			case res.Err == nil:
+				results[idx].Outcome = ImportCreated
+				results[idx].RecordID = res.ID
+			case errors.Is(res.Err, domain.ErrConflict):
+				results[idx].Outcome = ImportConflict
+				results[idx].Error = "record already exists"
+			default:
+				return res.Err
+			}
+		}
+		pending = pending[:0]
+		batch = batch[:0]
+		return nil
+	}
+
+	for i, row := range rows {
+		results[i].Line = row.Line
+		if row.Err != nil {
+			results[i].Outcome = ImportInvalid
+			results[i].Error = row.Err.Error()
+			continue
+		}
+		rec, err := newRecord(row.Input, s.nowFn().UTC())
+		if err == nil {
+			s.assignPort(&rec)
+			err = s.assignTerminal(ctx, &rec)
+		}
+		if err == nil {
+			err = s.applyTariff(ctx, &rec, row.Input)
+		}
+		if err == nil {
+			err = s.assignFreeTime(ctx, &rec, row.Input)
+		}
+		if err != nil {
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				return nil, err
+			}
+			results[i].Outcome = ImportInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+		pending = append(pending, i)
+		batch = append(batch, rec)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func importInput(booking, serie string) domain.CreateRecordInput {
+	return domain.CreateRecordInput{
+		Nave:            "NYK DENEB",
+		Viaje:           "072E",
+		Cliente:         "CAPITAL PACIFICO, S.A.",
+		Booking:         booking,
+		ContenedorSerie: serie,
+		FechaReal:       time.Date(2026, 2, 17, 0, 0, 0, 0, time.UTC),
+		PuertoDescargue: "BALBOA",
+		UsuarioFirma:    "user-1",
+	}
+}
+
+func TestImportRecordsReportsEveryRow(t *testing.T) {
+	var batches [][]domain.Record
+	svc := NewRecordService(mockRepo{insertBatchFn: func(_ context.Context, records []domain.Record) ([]domain.BatchInsertResult, error) {
+		batches = append(batches, records)
+		return []domain.BatchInsertResult{{ID: 501}, {Err: domain.ErrConflict}}, nil
+	}})
+
+	noSerie := importInput("BK3", "")
+	results, err := svc.ImportRecords(context.Background(), []ImportRow{
+		{Line: 2, Input: importInput("BK1", "YMLU5374938")},
+		{Line: 3, Err: errors.New("fecha_real must use YYYY-MM-DD")},
+		{Line: 4, Input: importInput("BK2", "YMLU5374938")},
+		{Line: 5, Input: noSerie},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("expected one batch of two records, got %+v", batches)
+	}
+	if batches[0][0].TituloTerminal != "TERMINAL PACIFICO - BALBOA" || batches[0][0].Status != domain.StatusIssued {
+		t.Fatalf("expected records built like Create, got %+v", batches[0][0])
+	}
+
+	want := []ImportResult{
+		{Line: 2, Outcome: ImportCreated, RecordID: 501},
+		{Line: 3, Outcome: ImportInvalid},
+		{Line: 4, Outcome: ImportConflict},
+		{Line: 5, Outcome: ImportInvalid},
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Line != w.Line || got.Outcome != w.Outcome || got.RecordID != w.RecordID {
+			t.Fatalf("row %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+	if results[3].Error == "" {
+		t.Fatal("expected validation message for invalid row")
+	}
+}
+
+func TestImportRecordsSplitsBatches(t *testing.T) {
+	var sizes []int
+	svc := NewRecordService(mockRepo{insertBatchFn: func(_ context.Context, records []domain.Record) ([]domain.BatchInsertResult, error) {
+		sizes = append(sizes, len(records))
+		return make([]domain.BatchInsertResult, len(records)), nil
+	}})
+
+	rows := make([]ImportRow, importBatchSize+1)
+	for i := range rows {
+		rows[i] = ImportRow{Line: i + 2, Input: importInput("BK1", "YMLU5374938")}
+	}
+	if _, err := svc.ImportRecords(context.Background(), rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != importBatchSize || sizes[1] != 1 {
+		t.Fatalf("unexpected batch sizes: %v", sizes)
+	}
+}
+
+func TestImportRecordsRejectsEmptyFile(t *testing.T) {
+	svc := NewRecordService(mockRepo{})
+	if _, err := svc.ImportRecords(context.Background(), nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
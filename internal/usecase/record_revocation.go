@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+var ErrRevocationsUnavailable = errors.New("revocations unavailable")
+
+// QRValidation is the outcome of validating a QR token against the stored record.
+type QRValidation struct {
+	Record     domain.Record
+	Revocation *domain.Revocation
+	// Status is the effective lifecycle state at validation time.
+	Status domain.RecordStatus
+}
+
+// Valid reports whether the pass may be accepted at the gate.
+func (v QRValidation) Valid() bool {
+	return v.Status == domain.StatusIssued && v.Revocation == nil
+}
+
+// WithRevocations enables pass revocation and the revocation check during validation.
+func (s *RecordService) WithRevocations(repo domain.RevocationRepository) *RecordService {
+	s.revocations = repo
+	return s
+}
+
+// Revoke cancels a pass so later validations report it as revoked. The revocation and the
+// status change are written together by the repository.
+func (s *RecordService) Revoke(ctx context.Context, recordID int64, reason, revokedBy string) (domain.Revocation, error) {
+	if s.revocations == nil {
+		return domain.Revocation{}, ErrRevocationsUnavailable
+	}
+	if strings.TrimSpace(revokedBy) == "" {
+		return domain.Revocation{}, domain.ErrUnauthorized
+	}
+	if recordID <= 0 || strings.TrimSpace(reason) == "" {
+		return domain.Revocation{}, domain.ErrInvalidInput
+	}
+	rec, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Revocation{}, err
+	}
+	current := rec.Status
+	if current == "" {
+		current = domain.StatusIssued
+	}
+	if !current.CanTransitionTo(domain.StatusRevoked) {
+		return domain.Revocation{}, fmt.Errorf("%w: record is %s", domain.ErrConflict, current)
+	}
+
+	rev := domain.Revocation{
+		RecordID:  recordID,
+		Reason:    strings.TrimSpace(reason),
+		RevokedBy: strings.TrimSpace(revokedBy),
+		RevokedAt: s.nowFn().UTC(),
+	}
+	if err := s.revocations.Revoke(ctx, rev, current); err != nil {
+		return domain.Revocation{}, err
+	}
+	return rev, nil
+}
+
+// ValidateQRToken verifies the token, loads the record, checks the revocation list and
+// computes the effective lifecycle state. For an expired token the returned validation carries
+// only the record id alongside ErrExpiredQRToken.
+func (s *RecordService) ValidateQRToken(ctx context.Context, token string) (QRValidation, error) {
+	rec, err := s.FindByQRToken(ctx, token)
+	if errors.Is(err, ErrExpiredQRToken) {
+		return QRValidation{Record: domain.Record{ID: rec.ID}}, err
+	}
+	if err != nil {
+		return QRValidation{}, err
+	}
+
+	result := QRValidation{Record: rec, Status: rec.EffectiveStatus(s.nowFn().In(s.Location(ctx, rec)))}
+	if s.revocations == nil {
+		return result, nil
+	}
+	rev, err := s.revocations.FindByRecordID(ctx, rec.ID)
+	switch {
+	case err == nil:
+		result.Revocation = &rev
+		result.Status = domain.StatusRevoked
+	case !errors.Is(err, domain.ErrNotFound):
+		return QRValidation{}, err
+	}
+	return result, nil
+}
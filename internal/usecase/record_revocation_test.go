@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type mockRevocations struct {
+	revokeFn func(ctx context.Context, rev domain.Revocation, from domain.RecordStatus) error
+	findFn   func(ctx context.Context, recordID int64) (domain.Revocation, error)
+}
+
+func (m mockRevocations) Revoke(ctx context.Context, rev domain.Revocation, from domain.RecordStatus) error {
+	return m.revokeFn(ctx, rev, from)
+}
+
+func (m mockRevocations) FindByRecordID(ctx context.Context, recordID int64) (domain.Revocation, error) {
+	if m.findFn == nil {
+		return domain.Revocation{}, domain.ErrNotFound
+	}
+	return m.findFn(ctx, recordID)
+}
+
+func TestRevokeRecord(t *testing.T) {
+	var stored domain.Revocation
+	var from domain.RecordStatus
+	svc := NewRecordService(mockRepo{
+		updateStatusFn: func(context.Context, int64, domain.RecordStatus, domain.RecordStatus) error {
+			t.Fatal("the status must change in the revocation transaction, not in a separate write")
+			return nil
+		},
+	}).WithRevocations(mockRevocations{
+		revokeFn: func(_ context.Context, rev domain.Revocation, status domain.RecordStatus) error {
+			stored, from = rev, status
+			return nil
+		},
+	})
+
+	rev, err := svc.Revoke(context.Background(), 7, "  wrong container  ", "supervisor")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rev.Reason != "wrong container" || stored.RevokedBy != "supervisor" || stored.RecordID != 7 || from != domain.StatusIssued {
+		t.Fatalf("unexpected revocation stored: %+v from %s", stored, from)
+	}
+}
+
+func TestRevokeRecordFailureReturnsError(t *testing.T) {
+	svc := NewRecordService(mockRepo{}).WithRevocations(mockRevocations{
+		revokeFn: func(context.Context, domain.Revocation, domain.RecordStatus) error { return domain.ErrConflict },
+	})
+
+	if _, err := svc.Revoke(context.Background(), 7, "wrong container", "supervisor"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRevokeRecordRequiresReason(t *testing.T) {
+	svc := NewRecordService(mockRepo{}).WithRevocations(mockRevocations{})
+	_, err := svc.Revoke(context.Background(), 7, " ", "supervisor")
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestValidateQRTokenReportsRevocation(t *testing.T) {
+	svc := NewRecordService(
+		mockRepo{findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			return domain.Record{ID: id}, nil
+		}},
+		mockVerifier{verifyFn: func(_ string) (int64, error) { return 7, nil }},
+	).WithRevocations(mockRevocations{
+		findFn: func(_ context.Context, recordID int64) (domain.Revocation, error) {
+			return domain.Revocation{RecordID: recordID, Reason: "wrong container", RevokedBy: "supervisor"}, nil
+		},
+	})
+
+	result, err := svc.ValidateQRToken(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid() {
+		t.Fatal("expected revoked pass to be invalid")
+	}
+	if result.Revocation.Reason != "wrong container" {
+		t.Fatalf("unexpected revocation: %+v", result.Revocation)
+	}
+}
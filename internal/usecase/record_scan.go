@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+var ErrScanLogUnavailable = errors.New("scan log unavailable")
+
+const (
+	defaultScanListLimit = 100
+	maxScanListLimit     = 500
+)
+
+// WithScanLog enables recording every validation attempt in the gate scan log.
+func (s *RecordService) WithScanLog(repo domain.ScanRepository) *RecordService {
+	s.scans = repo
+	return s
+}
+
+// ValidateScan validates a token presented at the gate and appends the attempt to the scan log.
+// Infrastructure failures (verifier unavailable, database errors) are not logged as scans.
+func (s *RecordService) ValidateScan(ctx context.Context, token, scanner, clientIP string) (QRValidation, error) {
+	result, err := s.ValidateQRToken(ctx, token)
+	if logErr := s.logScan(ctx, result, err, scanner, clientIP); logErr != nil {
+		return QRValidation{}, errors.Join(err, logErr)
+	}
+	return result, err
+}
+
+func (s *RecordService) logScan(ctx context.Context, result QRValidation, err error, scanner, clientIP string) error {
+	outcome, recordID, ok := scanOutcome(result, err)
+	if !ok || s.scans == nil {
+		return nil
+	}
+	return s.scans.Insert(ctx, domain.Scan{
+		RecordID:  recordID,
+		Outcome:   outcome,
+		Scanner:   truncate(strings.TrimSpace(scanner), 100),
+		ClientIP:  truncate(strings.TrimSpace(clientIP), 45),
+		ScannedAt: s.nowFn().UTC(),
+	})
+}
+
+// ListScans returns the most recent scans of a record, newest first.
+func (s *RecordService) ListScans(ctx context.Context, recordID int64, limit int) ([]domain.Scan, error) {
+	if s.scans == nil {
+		return nil, ErrScanLogUnavailable
+	}
+	if recordID <= 0 || limit < 0 || limit > maxScanListLimit {
+		return nil, domain.ErrInvalidInput
+	}
+	if limit == 0 {
+		limit = defaultScanListLimit
+	}
+	if _, err := s.repo.FindByID(ctx, recordID); err != nil {
+		return nil, err
+	}
+	return s.scans.ListByRecordID(ctx, recordID, limit)
+}
+
+func scanOutcome(result QRValidation, err error) (domain.ScanOutcome, int64, bool) {
+	switch {
+	case err == nil, errors.Is(err, ErrPassNotConsumable):
+	case errors.Is(err, ErrInvalidQRToken):
+		return domain.ScanInvalidSignature, 0, true
+	case errors.Is(err, ErrExpiredQRToken):
+		return domain.ScanExpired, result.Record.ID, true
+	case errors.Is(err, domain.ErrNotFound):
+		return domain.ScanNotFound, 0, true
+	default:
+		return "", 0, false
+	}
+
+	recordID := result.Record.ID
+	if result.Valid() {
+		return domain.ScanValid, recordID, true
+	}
+	switch result.Status {
+	case domain.StatusRevoked:
+		return domain.ScanRevoked, recordID, true
+	case domain.StatusUsed:
+		return domain.ScanUsed, recordID, true
+	case domain.StatusSuperseded:
+		return domain.ScanSuperseded, recordID, true
+	default:
+		return domain.ScanExpired, recordID, true
+	}
+}
+
+func truncate(v string, max int) string {
+	if len(v) <= max {
+		return v
+	}
+	return v[:max]
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type mockScans struct {
+	inserted []domain.Scan
+}
+
+func (m *mockScans) Insert(_ context.Context, scan domain.Scan) error {
+	m.inserted = append(m.inserted, scan)
+	return nil
+}
+
+func (m *mockScans) ListByRecordID(_ context.Context, recordID int64, _ int) ([]domain.Scan, error) {
+	var out []domain.Scan
+	for _, scan := range m.inserted {
+		if scan.RecordID == recordID {
+			out = append(out, scan)
+		}
+	}
+	return out, nil
+}
+
+func TestValidateScanLogsEveryOutcome(t *testing.T) {
+	scans := &mockScans{}
+	svc := NewRecordService(
+		mockRepo{findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			if id == 404 {
+				return domain.Record{}, domain.ErrNotFound
+			}
+			return domain.Record{ID: id, Status: domain.StatusIssued}, nil
+		}},
+		mockVerifier{verifyFn: func(token string) (int64, error) {
+			switch token {
+			case "good":
+				return 7, nil
+			case "missing":
+				return 404, nil
+			case "expired":
+				return 8, ErrExpiredQRToken
+			default:
+				return 0, ErrInvalidQRToken
+			}
+		}},
+	).WithScanLog(scans)
+
+	if _, err := svc.ValidateScan(context.Background(), "good", "gate-1", "10.0.0.5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.ValidateScan(context.Background(), "forged", "gate-1", "10.0.0.5"); !errors.Is(err, ErrInvalidQRToken) {
+		t.Fatalf("expected ErrInvalidQRToken, got %v", err)
+	}
+	if _, err := svc.ValidateScan(context.Background(), "missing", "gate-2", "10.0.0.6"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.ValidateScan(context.Background(), "expired", "gate-2", "10.0.0.6"); !errors.Is(err, ErrExpiredQRToken) {
+		t.Fatalf("expected ErrExpiredQRToken, got %v", err)
+	}
+
+	want := []domain.Scan{
+		{RecordID: 7, Outcome: domain.ScanValid, Scanner: "gate-1", ClientIP: "10.0.0.5"},
+		{RecordID: 0, Outcome: domain.ScanInvalidSignature, Scanner: "gate-1", ClientIP: "10.0.0.5"},
+		{RecordID: 0, Outcome: domain.ScanNotFound, Scanner: "gate-2", ClientIP: "10.0.0.6"},
+		{RecordID: 8, Outcome: domain.ScanExpired, Scanner: "gate-2", ClientIP: "10.0.0.6"},
+	}
+	if len(scans.inserted) != len(want) {
+		t.Fatalf("expected %d scans, got %d", len(want), len(scans.inserted))
+	}
+	for i, w := range want {
+		got := scans.inserted[i]
+		if got.RecordID != w.RecordID || got.Outcome != w.Outcome || got.Scanner != w.Scanner || got.ClientIP != w.ClientIP {
+			t.Errorf("scan %d: expected %+v, got %+v", i, w, got)
+		}
+		if got.ScannedAt.IsZero() {
+			t.Errorf("scan %d: scanned_at not set", i)
+		}
+	}
+}
+
+func TestValidateScanSkipsInfrastructureErrors(t *testing.T) {
+	scans := &mockScans{}
+	svc := NewRecordService(mockRepo{}).WithScanLog(scans)
+
+	if _, err := svc.ValidateScan(context.Background(), "abc", "gate-1", "10.0.0.5"); !errors.Is(err, ErrQRVerifierUnavailable) {
+		t.Fatalf("expected ErrQRVerifierUnavailable, got %v", err)
+	}
+	if len(scans.inserted) != 0 {
+		t.Fatalf("expected no scan logged, got %d", len(scans.inserted))
+	}
+}
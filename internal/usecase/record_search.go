@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+const (
+	defaultRecordSearchLimit = 50
+	maxRecordSearchLimit     = 200
+)
+
+// RecordPage is one page of search results. NextCursor is the id to pass as AfterID for the
+// following page, or 0 when there are no more results.
+type RecordPage struct {
+	Records    []domain.Record
+	NextCursor int64
+}
+
+// SearchRecords lists records matching filter, newest first, using keyset pagination on id.
+func (s *RecordService) SearchRecords(ctx context.Context, filter domain.RecordFilter) (RecordPage, error) {
+	if filter.Limit < 0 || filter.Limit > maxRecordSearchLimit || filter.AfterID < 0 {
+		return RecordPage{}, domain.ErrInvalidInput
+	}
+	if filter.Rama != "" && filter.Rama != "internacional" && filter.Rama != "nacional" {
+		return RecordPage{}, domain.ErrInvalidInput
+	}
+	if invalidRange(filter.EmisionFrom, filter.EmisionTo) || invalidRange(filter.CreatedFrom, filter.CreatedTo) {
+		return RecordPage{}, domain.ErrInvalidInput
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultRecordSearchLimit
+	}
+
+	// Fetch one extra row to know whether another page exists.
+	filter.Limit = limit + 1
+	records, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		return RecordPage{}, err
+	}
+
+	page := RecordPage{Records: records}
+	if len(records) > limit {
+		page.Records = records[:limit]
+		page.NextCursor = page.Records[limit-1].ID
+	}
+	return page, nil
+}
+
+func invalidRange(from, to time.Time) bool {
+	return !from.IsZero() && !to.IsZero() && !from.Before(to)
+}
+
+// ExportRecords calls fn for every record matching filter, oldest first, without paging. The
+// filter is validated before any record is read.
+func (s *RecordService) ExportRecords(ctx context.Context, filter domain.RecordFilter, fn func(domain.Record) error) error {
+	if filter.Rama != "" && filter.Rama != "internacional" && filter.Rama != "nacional" {
+		return domain.ErrInvalidInput
+	}
+	if invalidRange(filter.EmisionFrom, filter.EmisionTo) || invalidRange(filter.CreatedFrom, filter.CreatedTo) {
+		return domain.ErrInvalidInput
+	}
+	filter.Limit, filter.AfterID = 0, 0
+	return s.repo.Stream(ctx, filter, func(rec domain.Record) error {
+		s.fillTituloTerminal(ctx, &rec)
+		return fn(rec)
+	})
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func TestSearchRecordsPaginates(t *testing.T) {
+	var got domain.RecordFilter
+	svc := NewRecordService(mockRepo{searchFn: func(_ context.Context, filter domain.RecordFilter) ([]domain.Record, error) {
+		got = filter
+		return []domain.Record{{ID: 9}, {ID: 8}, {ID: 7}}, nil
+	}})
+
+	page, err := svc.SearchRecords(context.Background(), domain.RecordFilter{Booking: "BK001", Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Limit != 3 || got.Booking != "BK001" {
+		t.Fatalf("unexpected repository filter: %+v", got)
+	}
+	if len(page.Records) != 2 || page.NextCursor != 8 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestSearchRecordsLastPageHasNoCursor(t *testing.T) {
+	svc := NewRecordService(mockRepo{searchFn: func(_ context.Context, filter domain.RecordFilter) ([]domain.Record, error) {
+		if filter.Limit != defaultRecordSearchLimit+1 {
+			t.Fatalf("expected default limit, got %d", filter.Limit)
+		}
+		return []domain.Record{{ID: 3}}, nil
+	}})
+
+	page, err := svc.SearchRecords(context.Background(), domain.RecordFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Records) != 1 || page.NextCursor != 0 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestSearchRecordsRejectsInvalidFilter(t *testing.T) {
+	svc := NewRecordService(mockRepo{})
+	day := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)
+	for _, filter := range []domain.RecordFilter{
+		{Limit: maxRecordSearchLimit + 1},
+		{Rama: "otra"},
+		{EmisionFrom: day, EmisionTo: day},
+	} {
+		if _, err := svc.SearchRecords(context.Background(), filter); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("filter %+v: expected ErrInvalidInput, got %v", filter, err)
+		}
+	}
+}
+
+func TestExportRecordsStreamsWithoutPaging(t *testing.T) {
+	var got domain.RecordFilter
+	svc := NewRecordService(mockRepo{streamFn: func(_ context.Context, filter domain.RecordFilter, fn func(domain.Record) error) error {
+		got = filter
+		for _, rec := range []domain.Record{{ID: 1, PuertoDescargue: "BALBOA"}, {ID: 2, TituloTerminal: "PANAMA PORTS COMPANY (RODMAN)"}} {
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}})
+
+	var titles []string
+	err := svc.ExportRecords(context.Background(), domain.RecordFilter{Cliente: "CLIENTE", Limit: 20, AfterID: 9}, func(rec domain.Record) error {
+		titles = append(titles, rec.TituloTerminal)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Limit != 0 || got.AfterID != 0 || got.Cliente != "CLIENTE" {
+		t.Fatalf("unexpected repository filter: %+v", got)
+	}
+	if len(titles) != 2 || titles[0] != "TERMINAL PACIFICO - BALBOA" {
+		t.Fatalf("unexpected terminal titles: %v", titles)
+	}
+}
+
+func TestExportRecordsRejectsInvalidRange(t *testing.T) {
+	svc := NewRecordService(mockRepo{})
+	day := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)
+	err := svc.ExportRecords(context.Background(), domain.RecordFilter{EmisionFrom: day, EmisionTo: day}, func(domain.Record) error {
+		t.Fatal("no record expected")
+		return nil
+	})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
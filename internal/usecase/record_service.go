@@ -13,6 +13,24 @@ import (
 type RecordService struct {
 	repo       domain.RecordRepository
 	qrVerifier QRTokenVerifier
+	qrIssuer   QRTokenIssuer
+
+	revocations domain.RevocationRepository
+	scans       domain.ScanRepository
+	extensions  domain.ExtensionRepository
+	nowFn       func() time.Time
+
+	terminals          *TerminalCatalog
+	rejectUnknownPorts bool
+	ports              PortDirectory
+	freeTime           *FreeTimeRules
+	location           *time.Location
+	tariffs            *ClientTariffs
+}
+
+// PortDirectory resolves free-text puerto_descargue values to UN/LOCODE ports.
+type PortDirectory interface {
+	Resolve(text string) (domain.Port, bool)
 }
 
 func NewRecordService(repo domain.RecordRepository, qrVerifier ...QRTokenVerifier) *RecordService {
@@ -20,103 +38,271 @@ func NewRecordService(repo domain.RecordRepository, qrVerifier ...QRTokenVerifie
 	if len(qrVerifier) > 0 {
 		verifier = qrVerifier[0]
 	}
-	return &RecordService{repo: repo, qrVerifier: verifier}
+	return &RecordService{repo: repo, qrVerifier: verifier, nowFn: time.Now}
+}
+
+// WithQRTokenIssuer enables native QR token issuance on the service.
+func (s *RecordService) WithQRTokenIssuer(issuer QRTokenIssuer) *RecordService {
+	s.qrIssuer = issuer
+	return s
+}
+
+// WithTerminals resolves titulo_terminal through catalog instead of the built-in defaults. With
+// rejectUnknown, records whose puerto_descargue is not catalogued are refused; otherwise they are
+// accepted with a fallback title and flagged with UnknownTerminal.
+func (s *RecordService) WithTerminals(catalog *TerminalCatalog, rejectUnknown bool) *RecordService {
+	s.terminals = catalog
+	s.rejectUnknownPorts = rejectUnknown
+	return s
+}
+
+// WithPorts normalizes puerto_descargue to a UN/LOCODE code and display name through dir.
+func (s *RecordService) WithPorts(dir PortDirectory) *RecordService {
+	s.ports = dir
+	return s
+}
+
+// WithFreeTime selects the free-time policy of new passes (per client or terminal) and counts
+// free days with it instead of always using calendar days.
+func (s *RecordService) WithFreeTime(rules *FreeTimeRules) *RecordService {
+	s.freeTime = rules
+	return s
+}
+
+// WithTariffs fills dias_libre of new passes from the client's tariff and enforces its maximum.
+func (s *RecordService) WithTariffs(tariffs *ClientTariffs) *RecordService {
+	s.tariffs = tariffs
+	return s
 }
 
 func (s *RecordService) Create(ctx context.Context, in domain.CreateRecordInput) (int64, domain.Record, error) {
-	if strings.TrimSpace(in.UsuarioFirma) == "" {
-		return 0, domain.Record{}, domain.ErrUnauthorized
+	rec, err := newRecord(in, s.nowFn().UTC())
+	if err != nil {
+		return 0, domain.Record{}, err
+	}
+	s.assignPort(&rec)
+	if err := s.assignTerminal(ctx, &rec); err != nil {
+		return 0, domain.Record{}, err
+	}
+	if err := s.applyTariff(ctx, &rec, in); err != nil {
+		return 0, domain.Record{}, err
+	}
+	if err := s.assignFreeTime(ctx, &rec, in); err != nil {
+		return 0, domain.Record{}, err
+	}
+
+	id, err := s.repo.Insert(ctx, rec)
+	if err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return 0, domain.Record{}, domain.ErrConflict
+		}
+		return 0, domain.Record{}, err
 	}
-	if strings.TrimSpace(in.Nave) == "" || strings.TrimSpace(in.Viaje) == "" || strings.TrimSpace(in.Cliente) == "" {
-		return 0, domain.Record{}, domain.ErrInvalidInput
+
+	rec.ID = id
+	return id, rec, nil
+}
+
+// newRecord applies the creation rules to in and builds the record to insert, issued at now and
+// leaving TituloTerminal to assignTerminal. Validation failures wrap domain.ErrInvalidInput with
+// the offending field.
+func newRecord(in domain.CreateRecordInput, now time.Time) (domain.Record, error) {
+	if strings.TrimSpace(in.UsuarioFirma) == "" {
+		return domain.Record{}, domain.ErrUnauthorized
 	}
-	if strings.TrimSpace(in.Booking) == "" || strings.TrimSpace(in.PuertoDescargue) == "" {
-		return 0, domain.Record{}, domain.ErrInvalidInput
+	for _, required := range []struct{ field, value string }{
+		{"nave", in.Nave},
+		{"viaje", in.Viaje},
+		{"cliente", in.Cliente},
+		{"booking", in.Booking},
+		{"puerto_descargue", in.PuertoDescargue},
+	} {
+		if strings.TrimSpace(required.value) == "" {
+			return domain.Record{}, fmt.Errorf("%w: %s is required", domain.ErrInvalidInput, required.field)
+		}
 	}
 
 	diasLibre := 0
 	if in.DiasLibre != nil {
 		if *in.DiasLibre < 0 {
-			return 0, domain.Record{}, domain.ErrInvalidInput
+			return domain.Record{}, fmt.Errorf("%w: dias_libre must not be negative", domain.ErrInvalidInput)
 		}
 		diasLibre = *in.DiasLibre
 	}
 
-	rama, contenedor, transportista, err := resolveContenedorData(in.Rama, in.ContenedorSerie, in.CodigoISO, in.Transportista)
+	fechaReal := in.FechaReal
+	if fechaReal.IsZero() {
+		if in.LibreRetencionHasta.IsZero() {
+			return domain.Record{}, fmt.Errorf("%w: fecha_real is required", domain.ErrInvalidInput)
+		}
+		fechaReal = in.LibreRetencionHasta.AddDate(0, 0, -diasLibre)
+	}
+
+	maxUses := 0
+	if in.MaxUses != nil {
+		if *in.MaxUses <= 0 {
+			return domain.Record{}, fmt.Errorf("%w: max_uses must be at least 1", domain.ErrInvalidInput)
+		}
+		maxUses = *in.MaxUses
+	}
+
+	lines := in.Containers
+	if len(lines) == 0 && (strings.TrimSpace(in.ContenedorSerie) != "" || strings.TrimSpace(in.CodigoISO) != "") {
+		lines = []domain.ContainerLine{{Serie: in.ContenedorSerie, CodigoISO: in.CodigoISO}}
+	}
+	rama, containers, contenedor, transportista, err := resolveContenedorData(in.Rama, lines, in.Transportista)
 	if err != nil {
-		return 0, domain.Record{}, domain.ErrInvalidInput
+		return domain.Record{}, fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
 	}
 
-	rec := domain.Record{
-		Emision:             time.Now().UTC(),
+	return domain.Record{
+		Emision:             now,
 		Nave:                strings.TrimSpace(in.Nave),
 		Viaje:               strings.TrimSpace(in.Viaje),
 		Cliente:             strings.TrimSpace(in.Cliente),
 		Booking:             strings.TrimSpace(in.Booking),
 		Rama:                rama,
 		Contenedor:          contenedor,
+		Containers:          containers,
 		PuertoDescargue:     strings.TrimSpace(in.PuertoDescargue),
-		LibreRetencionHasta: in.FechaReal.AddDate(0, 0, diasLibre),
+		FechaReal:           in.FechaReal,
+		LibreRetencionHasta: freeTimeUntil(fechaReal, diasLibre),
 		DiasLibre:           diasLibre,
+		FreeTimePolicy:      domain.FreeTimeCalendarDays,
 		Transportista:       transportista,
-		TituloTerminal:      resolveTituloTerminal(in.PuertoDescargue),
 		UsuarioFirma:        strings.TrimSpace(in.UsuarioFirma),
-		CreatedAt:           time.Now().UTC(),
+		Status:              domain.StatusIssued,
+		MaxUses:             maxUses,
+		Version:             1,
+		CreatedAt:           now,
+	}, nil
+}
+
+// applyTariff fills DiasLibre from the client's tariff when the request omits it and rejects
+// values above the contracted maximum unless in.OverrideTariff. Legacy payloads without
+// fecha_real keep the dias_libre they were sent with, as it was used for their deadline.
+func (s *RecordService) applyTariff(ctx context.Context, rec *domain.Record, in domain.CreateRecordInput) error {
+	if s.tariffs == nil {
+		return nil
+	}
+	tariff, ok, err := s.tariffs.Find(ctx, *rec)
+	if err != nil || !ok {
+		return err
 	}
+	if in.DiasLibre == nil && !in.FechaReal.IsZero() {
+		rec.DiasLibre = tariff.DefaultDiasLibre
+	}
+	if rec.DiasLibre > tariff.MaxDiasLibre && !in.OverrideTariff {
+		return &domain.TariffLimitError{Cliente: rec.Cliente, DiasLibre: rec.DiasLibre, Max: tariff.MaxDiasLibre}
+	}
+	return nil
+}
 
-	id, err := s.repo.Insert(ctx, rec)
-	if err != nil {
-		if errors.Is(err, domain.ErrConflict) {
-			return 0, domain.Record{}, domain.ErrConflict
+// assignFreeTime selects the free-time policy for a new pass and computes its deadline with it.
+// Legacy payloads without fecha_real keep calendar days, so the libre_retencion_hasta they were
+// sent with is stored unchanged.
+func (s *RecordService) assignFreeTime(ctx context.Context, rec *domain.Record, in domain.CreateRecordInput) error {
+	rec.FreeTimePolicy = domain.FreeTimeCalendarDays
+	if s.freeTime != nil && !in.FechaReal.IsZero() {
+		t, _, err := s.LookupTerminal(ctx, *rec)
+		if err != nil {
+			return err
+		}
+		if rec.FreeTimePolicy, err = s.freeTime.Select(ctx, rec.Cliente, t); err != nil {
+			return err
 		}
-		return 0, domain.Record{}, err
 	}
+	return s.applyFreeTime(ctx, rec, rec.BaseFechaReal())
+}
 
-	rec.ID = id
-	return id, rec, nil
+// applyFreeTime recomputes LibreRetencionHasta by counting rec.DiasLibre from fechaReal with the
+// policy recorded on the pass and the current holidays of its terminal. Callers take fechaReal
+// from the pass before changing DiasLibre, as it is derived from it for legacy passes.
+func (s *RecordService) applyFreeTime(ctx context.Context, rec *domain.Record, fechaReal time.Time) error {
+	if s.freeTime == nil || rec.FreeTimePolicy == "" || rec.FreeTimePolicy == domain.FreeTimeCalendarDays {
+		rec.LibreRetencionHasta = freeTimeUntil(fechaReal, rec.DiasLibre)
+		return nil
+	}
+	t, _, err := s.LookupTerminal(ctx, *rec)
+	if err != nil {
+		return err
+	}
+	calc, err := s.freeTime.Calculator(ctx, rec.FreeTimePolicy, t.Code)
+	if err != nil {
+		return err
+	}
+	rec.LibreRetencionHasta = calc.Until(fechaReal, rec.DiasLibre)
+	return nil
 }
 
-func resolveContenedorData(rama, contenedorSerie, codigoISO, transportista string) (string, string, string, error) {
-	normalizedRama := strings.ToLower(strings.TrimSpace(rama))
-	if normalizedRama == "" {
-		if strings.TrimSpace(contenedorSerie) != "" {
-			normalizedRama = "internacional"
-		} else if strings.TrimSpace(codigoISO) != "" || strings.TrimSpace(transportista) != "" {
-			normalizedRama = "nacional"
-		}
+// freeTimeUntil returns the last day of free retention for a container discharged on fechaReal,
+// counting calendar days.
+func freeTimeUntil(fechaReal time.Time, diasLibre int) time.Time {
+	return fechaReal.AddDate(0, 0, diasLibre)
+}
+
+// assignPort stores the UN/LOCODE and display name of rec.PuertoDescargue, flagging the record
+// with UnknownPort when the directory does not know it. Without a directory both are left empty.
+func (s *RecordService) assignPort(rec *domain.Record) {
+	if s.ports == nil {
+		rec.PuertoLocode, rec.PuertoNombre = "", ""
+		return
 	}
+	p, ok := s.ports.Resolve(rec.PuertoDescargue)
+	if !ok {
+		rec.PuertoLocode, rec.PuertoNombre, rec.UnknownPort = "", "", true
+		return
+	}
+	rec.PuertoLocode, rec.PuertoNombre, rec.UnknownPort = p.Code, p.DisplayName(), false
+}
 
-	switch normalizedRama {
-	case "internacional":
-		serie := strings.TrimSpace(contenedorSerie)
-		if serie == "" {
-			return "", "", "", errors.New("contenedor_serie is required for internacional")
+// LookupTerminal finds the catalogue terminal for the port of rec: by its UN/LOCODE first, then
+// by puerto_descargue.
+func (s *RecordService) LookupTerminal(ctx context.Context, rec domain.Record) (domain.Terminal, bool, error) {
+	resolve := func(puerto string) (domain.Terminal, bool, error) {
+		if s.terminals == nil {
+			t, ok := domain.MatchTerminal(domain.DefaultTerminals(), puerto)
+			return t, ok, nil
 		}
-		return "internacional", serie, "", nil
-	case "nacional":
-		iso := strings.TrimSpace(codigoISO)
-		trans := strings.TrimSpace(transportista)
-		if iso == "" || trans == "" {
-			return "", "", "", errors.New("codigo_iso and transportista are required for nacional")
+		return s.terminals.Resolve(ctx, puerto)
+	}
+	if rec.PuertoLocode != "" {
+		if t, ok, err := resolve(rec.PuertoLocode); err != nil || ok {
+			return t, ok, err
 		}
-		return "nacional", fmt.Sprintf("1 X %s", iso), trans, nil
-	default:
-		return "", "", "", errors.New("rama could not be inferred; provide contenedor_serie or codigo_iso+transportista")
 	}
+	return resolve(rec.PuertoDescargue)
 }
 
-func resolveTituloTerminal(puertoDescargue string) string {
-	puerto := strings.ToUpper(strings.TrimSpace(puertoDescargue))
-	switch {
-	case strings.Contains(puerto, "BALBOA"):
-		return "TERMINAL PACIFICO - BALBOA"
-	case strings.Contains(puerto, "CRISTOBAL"):
-		return "TERMINAL ATLANTICO - CRISTOBAL"
-	case strings.Contains(puerto, "MANZANILLO"):
-		return "MANZANILLO INTERNATIONAL TERMINAL"
-	default:
-		return "TERMINAL " + strings.TrimSpace(puertoDescargue)
+// assignTerminal sets the title of the terminal serving the port of rec, applying the
+// unknown-port policy chosen in WithTerminals.
+func (s *RecordService) assignTerminal(ctx context.Context, rec *domain.Record) error {
+	t, ok, err := s.LookupTerminal(ctx, *rec)
+	if err != nil {
+		return err
 	}
+	if ok {
+		rec.TituloTerminal, rec.UnknownTerminal = t.Title, false
+		return nil
+	}
+	if s.rejectUnknownPorts {
+		return fmt.Errorf("%w: %w: %s", domain.ErrInvalidInput, domain.ErrUnknownTerminal, strings.TrimSpace(rec.PuertoDescargue))
+	}
+	rec.TituloTerminal, rec.UnknownTerminal = domain.FallbackTerminalTitle(rec.PuertoDescargue), true
+	return nil
+}
+
+// fillTituloTerminal derives the title of records stored without one. It only affects display,
+// so a catalogue failure falls back to the generic title instead of failing the read.
+func (s *RecordService) fillTituloTerminal(ctx context.Context, rec *domain.Record) {
+	if strings.TrimSpace(rec.TituloTerminal) != "" {
+		return
+	}
+	if t, ok, err := s.LookupTerminal(ctx, *rec); err == nil && ok {
+		rec.TituloTerminal = t.Title
+		return
+	}
+	rec.TituloTerminal = domain.FallbackTerminalTitle(rec.PuertoDescargue)
 }
 
 func (s *RecordService) FindByQRToken(ctx context.Context, token string) (domain.Record, error) {
@@ -124,8 +310,81 @@ func (s *RecordService) FindByQRToken(ctx context.Context, token string) (domain
 		return domain.Record{}, ErrQRVerifierUnavailable
 	}
 	recordID, err := s.qrVerifier.VerifyAndExtractRecordID(token)
+	if errors.Is(err, ErrExpiredQRToken) {
+		// The signature was verified, so the id is trustworthy; callers only use it for logging.
+		return domain.Record{ID: recordID}, err
+	}
 	if err != nil {
 		return domain.Record{}, err
 	}
 	return s.repo.FindByID(ctx, recordID)
 }
+
+// GetRecord returns the stored record by id for authenticated back-office lookups.
+func (s *RecordService) GetRecord(ctx context.Context, recordID int64) (domain.Record, error) {
+	if recordID <= 0 {
+		return domain.Record{}, domain.ErrInvalidInput
+	}
+	rec, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	s.fillTituloTerminal(ctx, &rec)
+	return rec, nil
+}
+
+// TransitionStatus moves a record to next when the lifecycle state machine allows it.
+func (s *RecordService) TransitionStatus(ctx context.Context, recordID int64, next domain.RecordStatus) (domain.Record, error) {
+	if recordID <= 0 || !next.Valid() {
+		return domain.Record{}, domain.ErrInvalidInput
+	}
+	rec, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	return s.transition(ctx, rec, next)
+}
+
+func (s *RecordService) transition(ctx context.Context, rec domain.Record, next domain.RecordStatus) (domain.Record, error) {
+	current := rec.Status
+	if current == "" {
+		current = domain.StatusIssued
+	}
+	if !current.CanTransitionTo(next) {
+		return domain.Record{}, fmt.Errorf("%w: cannot move record from %s to %s", domain.ErrConflict, current, next)
+	}
+	at := s.nowFn().UTC()
+	if err := s.repo.UpdateStatus(ctx, rec.ID, current, next, at); err != nil {
+		return domain.Record{}, err
+	}
+	rec.Status = next
+	rec.StatusUpdatedAt = at
+	return rec, nil
+}
+
+// IssueQRToken mints a compact QR token for an existing record.
+func (s *RecordService) IssueQRToken(ctx context.Context, recordID int64, ttl time.Duration) (string, time.Time, error) {
+	_, token, expiresAt, err := s.FindWithQRToken(ctx, recordID, ttl)
+	return token, expiresAt, err
+}
+
+// FindWithQRToken loads a record and mints a compact QR token for it in one step,
+// as needed to print a pass.
+func (s *RecordService) FindWithQRToken(ctx context.Context, recordID int64, ttl time.Duration) (domain.Record, string, time.Time, error) {
+	if s.qrIssuer == nil {
+		return domain.Record{}, "", time.Time{}, ErrQRIssuerUnavailable
+	}
+	if recordID <= 0 {
+		return domain.Record{}, "", time.Time{}, domain.ErrInvalidInput
+	}
+	rec, err := s.repo.FindByID(ctx, recordID)
+	if err != nil {
+		return domain.Record{}, "", time.Time{}, err
+	}
+	s.fillTituloTerminal(ctx, &rec)
+	token, expiresAt, err := s.qrIssuer.Issue(recordID, ttl)
+	if err != nil {
+		return domain.Record{}, "", time.Time{}, err
+	}
+	return rec, token, expiresAt, nil
+}
@@ -18,8 +18,14 @@ func (m mockVerifier) VerifyAndExtractRecordID(token string) (int64, error) {
 }
 
 type mockRepo struct {
-	insertFn   func(ctx context.Context, r domain.Record) (int64, error)
-	findByIDFn func(ctx context.Context, id int64) (domain.Record, error)
+	insertFn       func(ctx context.Context, r domain.Record) (int64, error)
+	findByIDFn     func(ctx context.Context, id int64) (domain.Record, error)
+	updateStatusFn func(ctx context.Context, id int64, from, to domain.RecordStatus) error
+	consumeUseFn   func(ctx context.Context, id int64) error
+	searchFn       func(ctx context.Context, filter domain.RecordFilter) ([]domain.Record, error)
+	amendFn        func(ctx context.Context, r domain.Record, expectedVersion int, change domain.RecordVersion) error
+	insertBatchFn  func(ctx context.Context, records []domain.Record) ([]domain.BatchInsertResult, error)
+	streamFn       func(ctx context.Context, filter domain.RecordFilter, fn func(domain.Record) error) error
 }
 
 func (m mockRepo) Insert(ctx context.Context, r domain.Record) (int64, error) {
@@ -33,6 +39,46 @@ func (m mockRepo) FindByID(ctx context.Context, id int64) (domain.Record, error)
 	return m.findByIDFn(ctx, id)
 }
 
+func (m mockRepo) UpdateStatus(ctx context.Context, id int64, from, to domain.RecordStatus, _ time.Time) error {
+	if m.updateStatusFn == nil {
+		return nil
+	}
+	return m.updateStatusFn(ctx, id, from, to)
+}
+
+func (m mockRepo) ConsumeUse(ctx context.Context, id int64, _ time.Time) error {
+	if m.consumeUseFn == nil {
+		return nil
+	}
+	return m.consumeUseFn(ctx, id)
+}
+
+func (m mockRepo) Search(ctx context.Context, filter domain.RecordFilter) ([]domain.Record, error) {
+	if m.searchFn == nil {
+		return nil, nil
+	}
+	return m.searchFn(ctx, filter)
+}
+
+func (m mockRepo) Amend(ctx context.Context, r domain.Record, expectedVersion int, change domain.RecordVersion) error {
+	if m.amendFn == nil {
+		return nil
+	}
+	return m.amendFn(ctx, r, expectedVersion, change)
+}
+
+func (m mockRepo) ListVersions(_ context.Context, _ int64) ([]domain.RecordVersion, error) {
+	return nil, nil
+}
+
+func (m mockRepo) Stream(ctx context.Context, filter domain.RecordFilter, fn func(domain.Record) error) error {
+	return m.streamFn(ctx, filter, fn)
+}
+
+func (m mockRepo) InsertBatch(ctx context.Context, records []domain.Record) ([]domain.BatchInsertResult, error) {
+	return m.insertBatchFn(ctx, records)
+}
+
 func TestCreateSuccessInternacional(t *testing.T) {
 	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) {
 		return 99, nil
@@ -46,7 +92,7 @@ func TestCreateSuccessInternacional(t *testing.T) {
 		Cliente:         "CLIENTE TEST",
 		Booking:         "BK001",
 		Rama:            "internacional",
-		ContenedorSerie: "ABCU1234567",
+		ContenedorSerie: "ABCU1234560",
 		FechaReal:       fechaReal,
 		DiasLibre:       &dias,
 		PuertoDescargue: "Balboa",
@@ -58,12 +104,109 @@ func TestCreateSuccessInternacional(t *testing.T) {
 	if id != 99 {
 		t.Fatalf("unexpected id: %d", id)
 	}
-	if rec.Contenedor != "ABCU1234567" {
+	if rec.Contenedor != "ABCU1234560" {
 		t.Fatalf("unexpected contenedor: %s", rec.Contenedor)
 	}
 	if got := rec.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-12" {
 		t.Fatalf("unexpected libre retencion hasta: %s", got)
 	}
+	if !rec.FechaReal.Equal(fechaReal) {
+		t.Fatalf("expected fecha_real to be stored, got %s", rec.FechaReal)
+	}
+}
+
+func TestCreateStampsPassWithServiceClock(t *testing.T) {
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+		stored = r
+		return 1, nil
+	}})
+	panama, _ := time.LoadLocation(domain.DefaultTimezone)
+	// 23:30 in Panama is already the next day in UTC.
+	issuedAt := time.Date(2026, 2, 9, 23, 30, 0, 0, panama)
+	svc.nowFn = func() time.Time { return issuedAt }
+
+	_, _, err := svc.Create(context.Background(), domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001", ContenedorSerie: "ABCU1234560",
+		FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), PuertoDescargue: "Balboa", UsuarioFirma: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stored.Emision.Equal(issuedAt) || !stored.CreatedAt.Equal(issuedAt) || stored.Emision.Location() != time.UTC {
+		t.Fatalf("expected pass stamped at %s in UTC, got emision %s, created_at %s", issuedAt, stored.Emision, stored.CreatedAt)
+	}
+	if got := stored.Emision.In(svc.Location(context.Background(), stored)).Format("2006-01-02 15:04"); got != "2026-02-09 23:30" {
+		t.Fatalf("expected emision on the Panama day it was issued, got %s", got)
+	}
+}
+
+type stubPorts map[string]domain.Port
+
+func (s stubPorts) Resolve(text string) (domain.Port, bool) {
+	p, ok := s[text]
+	return p, ok
+}
+
+func TestCreateNormalizesPuertoDescargue(t *testing.T) {
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+		stored = r
+		return 1, nil
+	}}).WithPorts(stubPorts{"Puerto de Cristóbal": {Code: "PACTB", Name: "Cristóbal", Country: "PA", CountryName: "Panamá"}})
+	in := domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001", ContenedorSerie: "ABCU1234560",
+		FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), PuertoDescargue: "Puerto de Cristóbal", UsuarioFirma: "user-1",
+	}
+
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The accented spelling misses the CRISTOBAL alias; the terminal is found through the code.
+	if stored.PuertoLocode != "PACTB" || stored.PuertoNombre != "Cristóbal, Panamá" ||
+		stored.PuertoDescargue != "Puerto de Cristóbal" || stored.TituloTerminal != "TERMINAL ATLANTICO - CRISTOBAL" || rec.UnknownPort {
+		t.Fatalf("unexpected record: %+v", stored)
+	}
+
+	in.PuertoDescargue = "Muelle fiscal"
+	if _, rec, err = svc.Create(context.Background(), in); err != nil || !rec.UnknownPort || stored.PuertoLocode != "" {
+		t.Fatalf("expected unknown port to be flagged, got %+v (%v)", stored, err)
+	}
+}
+
+func TestCreateLegacyDeadlineLeavesFechaRealEmpty(t *testing.T) {
+	var stored domain.Record
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
+		stored = r
+		return 1, nil
+	}})
+
+	dias := 3
+	_, _, err := svc.Create(context.Background(), domain.CreateRecordInput{
+		Nave:                "NAVE TEST",
+		Viaje:               "VJ001",
+		Cliente:             "CLIENTE TEST",
+		Booking:             "BK001",
+		ContenedorSerie:     "ABCU1234560",
+		LibreRetencionHasta: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC),
+		DiasLibre:           &dias,
+		PuertoDescargue:     "Balboa",
+		UsuarioFirma:        "user-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The derived date is not stored as if the operator had entered it.
+	if !stored.FechaReal.IsZero() {
+		t.Fatalf("expected fecha_real to be left empty, got %s", stored.FechaReal)
+	}
+	if got := stored.BaseFechaReal().Format("2006-01-02"); got != "2026-02-09" {
+		t.Fatalf("unexpected derived fecha_real: %s", got)
+	}
+	if got := stored.LibreRetencionHasta.Format("2006-01-02"); got != "2026-02-12" {
+		t.Fatalf("expected libre_retencion_hasta as sent, got %s", got)
+	}
 }
 
 func TestCreateSuccessNacional(t *testing.T) {
@@ -98,6 +241,30 @@ func TestCreateSuccessNacional(t *testing.T) {
 	}
 }
 
+func TestCreateNacionalChecksContainerTypeCatalogue(t *testing.T) {
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) { return 1, nil }})
+	in := domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001", Rama: "nacional",
+		CodigoISO: " 45g1 ", Transportista: "TRANSPORTE SA", FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+		PuertoDescargue: "Cristobal", UsuarioFirma: "user-1",
+	}
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Contenedor != "1 X 45G1" {
+		t.Fatalf("expected canonical code, got %q", rec.Contenedor)
+	}
+	if got := ContainerDescription(rec); got != "1 X 40' HIGH CUBE" {
+		t.Fatalf("unexpected description %q", got)
+	}
+
+	in.CodigoISO = "40HC"
+	if _, _, err := svc.Create(context.Background(), in); !errors.Is(err, domain.ErrUnknownContainerType) || !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected unknown container type, got %v", err)
+	}
+}
+
 func TestCreateSuccessWithoutRamaInfersInternacional(t *testing.T) {
 	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, r domain.Record) (int64, error) {
 		if r.Rama != "internacional" {
@@ -112,7 +279,7 @@ func TestCreateSuccessWithoutRamaInfersInternacional(t *testing.T) {
 		Viaje:           "VJ001",
 		Cliente:         "CLIENTE TEST",
 		Booking:         "BK001",
-		ContenedorSerie: "ABCU1234567",
+		ContenedorSerie: "ABCU1234560",
 		FechaReal:       fechaReal,
 		PuertoDescargue: "Balboa",
 		UsuarioFirma:    "user-1",
@@ -120,7 +287,7 @@ func TestCreateSuccessWithoutRamaInfersInternacional(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if rec.Contenedor != "ABCU1234567" {
+	if rec.Contenedor != "ABCU1234560" {
 		t.Fatalf("unexpected contenedor: %s", rec.Contenedor)
 	}
 }
@@ -133,6 +300,29 @@ func TestCreateInvalidInput(t *testing.T) {
 	}
 }
 
+func TestCreateNormalizesAndChecksContainerNumber(t *testing.T) {
+	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) { return 1, nil }})
+	in := domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001", Rama: "internacional",
+		ContenedorSerie: "ymlu 537493-8", FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+		PuertoDescargue: "Balboa", UsuarioFirma: "user-1",
+	}
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Contenedor != "YMLU5374938" {
+		t.Fatalf("expected normalized contenedor, got %q", rec.Contenedor)
+	}
+
+	in.ContenedorSerie = "YMLU5374939"
+	_, _, err = svc.Create(context.Background(), in)
+	var cnErr *domain.ContainerNumberError
+	if !errors.Is(err, domain.ErrInvalidInput) || !errors.As(err, &cnErr) || cnErr.ExpectedCheckDigit != 8 {
+		t.Fatalf("expected check digit error, got %v", err)
+	}
+}
+
 func TestCreateConflict(t *testing.T) {
 	svc := NewRecordService(mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) {
 		return 0, domain.ErrConflict
@@ -145,7 +335,7 @@ func TestCreateConflict(t *testing.T) {
 		Cliente:         "CLIENTE TEST",
 		Booking:         "BK001",
 		Rama:            "internacional",
-		ContenedorSerie: "ABCU1234567",
+		ContenedorSerie: "ABCU1234560",
 		FechaReal:       fechaReal,
 		PuertoDescargue: "Balboa",
 		UsuarioFirma:    "user-1",
@@ -179,3 +369,75 @@ func TestFindByQRTokenSuccess(t *testing.T) {
 		t.Fatalf("expected id 7, got %d", rec.ID)
 	}
 }
+
+func TestIssueQRTokenRecordNotFound(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, _ int64) (domain.Record, error) {
+			return domain.Record{}, domain.ErrNotFound
+		},
+	}).WithQRTokenIssuer(NewCompactQRTokenIssuer("my-qr-secret", time.Hour, 24*time.Hour))
+
+	_, _, err := svc.IssueQRToken(context.Background(), 7, 0)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestValidateQRTokenReportsExpiredFreeTime(t *testing.T) {
+	svc := NewRecordService(
+		mockRepo{findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			return domain.Record{ID: id, Status: domain.StatusIssued, LibreRetencionHasta: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)}, nil
+		}},
+		mockVerifier{verifyFn: func(_ string) (int64, error) { return 7, nil }},
+	)
+
+	// Days end at midnight in Panama (UTC-5), not in UTC.
+	svc.nowFn = func() time.Time { return time.Date(2026, 3, 7, 4, 59, 59, 0, time.UTC) }
+	result, err := svc.ValidateQRToken(context.Background(), "abc")
+	if err != nil || !result.Valid() {
+		t.Fatalf("expected pass valid on its last free day, got %+v, %v", result, err)
+	}
+
+	svc.nowFn = func() time.Time { return time.Date(2026, 3, 7, 5, 0, 1, 0, time.UTC) }
+	result, err = svc.ValidateQRToken(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid() || result.Status != domain.StatusExpired {
+		t.Fatalf("expected expired pass, got %+v", result)
+	}
+}
+
+func TestTransitionStatusRejectsTerminalState(t *testing.T) {
+	svc := NewRecordService(mockRepo{
+		findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			return domain.Record{ID: id, Status: domain.StatusRevoked}, nil
+		},
+		updateStatusFn: func(_ context.Context, _ int64, _, _ domain.RecordStatus) error {
+			t.Fatal("update must not be attempted")
+			return nil
+		},
+	})
+
+	_, err := svc.TransitionStatus(context.Background(), 7, domain.StatusUsed)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestGetRecordFillsTituloTerminal(t *testing.T) {
+	svc := NewRecordService(mockRepo{findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+		return domain.Record{ID: id, PuertoDescargue: "RODMAN"}, nil
+	}})
+
+	if _, err := svc.GetRecord(context.Background(), 0); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	rec, err := svc.GetRecord(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.TituloTerminal == "" {
+		t.Fatal("expected titulo_terminal fallback")
+	}
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+// TerminalInput holds the editable fields of a catalogue terminal.
+type TerminalInput struct {
+	Code    string
+	Title   string
+	Address string
+	LogoURL string
+	Aliases []string
+	// FreeTimePolicy of passes discharged at the terminal; empty means calendar days.
+	FreeTimePolicy string
+	// Timezone is an IANA zone name; empty means the operating timezone of the service.
+	Timezone string
+}
+
+// TerminalCatalog manages the terminal catalogue and keeps it cached in process. The cache is
+// dropped on every change made through the catalogue and reloaded after ttl, so changes made by
+// other instances are picked up within ttl (0 keeps it until the next local change).
+type TerminalCatalog struct {
+	repo  domain.TerminalRepository
+	ttl   time.Duration
+	nowFn func() time.Time
+
+	mu         sync.RWMutex
+	cached     []domain.Terminal
+	loadedAt   time.Time
+	generation uint64
+}
+
+func NewTerminalCatalog(repo domain.TerminalRepository, ttl time.Duration) *TerminalCatalog {
+	return &TerminalCatalog{repo: repo, ttl: ttl, nowFn: time.Now}
+}
+
+// List returns every terminal ordered by code.
+func (c *TerminalCatalog) List(ctx context.Context) ([]domain.Terminal, error) {
+	terminals, err := c.terminals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.Terminal(nil), terminals...), nil
+}
+
+// Get returns the terminal with id.
+func (c *TerminalCatalog) Get(ctx context.Context, id int64) (domain.Terminal, error) {
+	if id <= 0 {
+		return domain.Terminal{}, domain.ErrInvalidInput
+	}
+	return c.repo.FindByID(ctx, id)
+}
+
+// Create adds a terminal. Codes and aliases already used by another terminal are ErrConflict.
+func (c *TerminalCatalog) Create(ctx context.Context, in TerminalInput) (domain.Terminal, error) {
+	t, err := newTerminal(in)
+	if err != nil {
+		return domain.Terminal{}, err
+	}
+	t.CreatedAt = c.nowFn().UTC()
+	t.UpdatedAt = t.CreatedAt
+	id, err := c.repo.Insert(ctx, t)
+	if err != nil {
+		return domain.Terminal{}, err
+	}
+	c.invalidate()
+	t.ID = id
+	return t, nil
+}
+
+// Update replaces the fields and aliases of terminal id. Records already issued keep their title.
+func (c *TerminalCatalog) Update(ctx context.Context, id int64, in TerminalInput) (domain.Terminal, error) {
+	if id <= 0 {
+		return domain.Terminal{}, domain.ErrInvalidInput
+	}
+	t, err := newTerminal(in)
+	if err != nil {
+		return domain.Terminal{}, err
+	}
+	t.ID = id
+	t.UpdatedAt = c.nowFn().UTC()
+	if err := c.repo.Update(ctx, t); err != nil {
+		return domain.Terminal{}, err
+	}
+	c.invalidate()
+	return c.repo.FindByID(ctx, id)
+}
+
+// Delete removes terminal id from the catalogue.
+func (c *TerminalCatalog) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return domain.ErrInvalidInput
+	}
+	if err := c.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	c.invalidate()
+	return nil
+}
+
+// Resolve finds the terminal for a puerto_descargue value with domain.MatchTerminal.
+func (c *TerminalCatalog) Resolve(ctx context.Context, puerto string) (domain.Terminal, bool, error) {
+	terminals, err := c.terminals(ctx)
+	if err != nil {
+		return domain.Terminal{}, false, err
+	}
+	t, ok := domain.MatchTerminal(terminals, puerto)
+	return t, ok, nil
+}
+
+func (c *TerminalCatalog) terminals(ctx context.Context) ([]domain.Terminal, error) {
+	c.mu.RLock()
+	cached, loadedAt, generation := c.cached, c.loadedAt, c.generation
+	c.mu.RUnlock()
+	if !loadedAt.IsZero() && (c.ttl <= 0 || c.nowFn().Sub(loadedAt) < c.ttl) {
+		return cached, nil
+	}
+
+	terminals, err := c.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	// A change committed while the list was loading may be missing from it; keep the cache
+	// empty so the next call reloads.
+	if c.generation == generation {
+		c.cached, c.loadedAt = terminals, c.nowFn()
+	}
+	c.mu.Unlock()
+	return terminals, nil
+}
+
+func (c *TerminalCatalog) invalidate() {
+	c.mu.Lock()
+	c.cached, c.loadedAt = nil, time.Time{}
+	c.generation++
+	c.mu.Unlock()
+}
+
+// newTerminal validates in and normalizes the code and aliases with domain.NormalizeTerminalKey.
+func newTerminal(in TerminalInput) (domain.Terminal, error) {
+	code := strings.ToUpper(strings.TrimSpace(in.Code))
+	if len(code) < 2 || len(code) > 20 || strings.IndexFunc(code, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) >= 0 {
+		return domain.Terminal{}, fmt.Errorf("%w: code must be 2-20 letters or digits", domain.ErrInvalidInput)
+	}
+	title := strings.TrimSpace(in.Title)
+	if title == "" || len(title) > 200 {
+		return domain.Terminal{}, fmt.Errorf("%w: title is required (at most 200 characters)", domain.ErrInvalidInput)
+	}
+	logoURL := strings.TrimSpace(in.LogoURL)
+	if logoURL != "" {
+		u, err := url.Parse(logoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return domain.Terminal{}, fmt.Errorf("%w: logo_url must be an absolute http(s) URL", domain.ErrInvalidInput)
+		}
+	}
+
+	seen := map[string]bool{code: true}
+	aliases := make([]string, 0, len(in.Aliases))
+	for _, raw := range in.Aliases {
+		alias := domain.NormalizeTerminalKey(raw)
+		if alias == "" || len(alias) > 150 {
+			return domain.Terminal{}, fmt.Errorf("%w: aliases must be 1-150 characters", domain.ErrInvalidInput)
+		}
+		if !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+
+	policy := domain.FreeTimeCalendarDays
+	if strings.TrimSpace(in.FreeTimePolicy) != "" {
+		var ok bool
+		if policy, ok = domain.ParseFreeTimePolicy(in.FreeTimePolicy); !ok {
+			return domain.Terminal{}, fmt.Errorf("%w: free_time_policy must be calendar_days or business_days", domain.ErrInvalidInput)
+		}
+	}
+
+	timezone := strings.TrimSpace(in.Timezone)
+	if timezone != "" {
+		// "Local" would follow the host of each instance.
+		if _, err := loadLocation(timezone); err != nil || timezone == "Local" {
+			return domain.Terminal{}, fmt.Errorf("%w: timezone must be an IANA zone name such as %s", domain.ErrInvalidInput, domain.DefaultTimezone)
+		}
+	}
+
+	return domain.Terminal{
+		Code:           code,
+		Title:          title,
+		Address:        strings.TrimSpace(in.Address),
+		LogoURL:        logoURL,
+		Aliases:        aliases,
+		FreeTimePolicy: policy,
+		Timezone:       timezone,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+type memTerminalRepo struct {
+	terminals []domain.Terminal
+	lists     int
+}
+
+func (m *memTerminalRepo) List(_ context.Context) ([]domain.Terminal, error) {
+	m.lists++
+	return append([]domain.Terminal(nil), m.terminals...), nil
+}
+
+func (m *memTerminalRepo) FindByID(_ context.Context, id int64) (domain.Terminal, error) {
+	for _, t := range m.terminals {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return domain.Terminal{}, domain.ErrNotFound
+}
+
+func (m *memTerminalRepo) Insert(_ context.Context, t domain.Terminal) (int64, error) {
+	for _, existing := range m.terminals {
+		if existing.Code == t.Code {
+			return 0, domain.ErrConflict
+		}
+	}
+	t.ID = int64(len(m.terminals) + 1)
+	m.terminals = append(m.terminals, t)
+	return t.ID, nil
+}
+
+func (m *memTerminalRepo) Update(_ context.Context, t domain.Terminal) error {
+	for i := range m.terminals {
+		if m.terminals[i].ID == t.ID {
+			t.CreatedAt = m.terminals[i].CreatedAt
+			m.terminals[i] = t
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *memTerminalRepo) Delete(_ context.Context, id int64) error {
+	for i := range m.terminals {
+		if m.terminals[i].ID == id {
+			m.terminals = append(m.terminals[:i], m.terminals[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func TestTerminalCatalogCachesUntilChanged(t *testing.T) {
+	repo := &memTerminalRepo{}
+	catalog := NewTerminalCatalog(repo, time.Minute)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	catalog.nowFn = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, ok, err := catalog.Resolve(ctx, "RODMAN"); err != nil || ok {
+		t.Fatalf("expected unknown port, got %v %v", ok, err)
+	}
+	created, err := catalog.Create(ctx, TerminalInput{Code: "parod", Title: "PANAMA PORTS COMPANY (RODMAN)", Aliases: []string{"Rodman", "rodman", " PSA  Rodman "}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Code != "PAROD" || len(created.Aliases) != 2 || created.Aliases[1] != "PSA RODMAN" {
+		t.Fatalf("unexpected terminal: %+v", created)
+	}
+
+	term, ok, err := catalog.Resolve(ctx, "Muelle Rodman")
+	if err != nil || !ok || term.Title != "PANAMA PORTS COMPANY (RODMAN)" {
+		t.Fatalf("expected the new terminal right after Create, got %+v %v %v", term, ok, err)
+	}
+	if _, _, err := catalog.Resolve(ctx, "RODMAN"); err != nil || repo.lists != 2 {
+		t.Fatalf("expected a cached read, got %d loads (%v)", repo.lists, err)
+	}
+
+	// Another instance changed the table: picked up once the ttl expires.
+	repo.terminals[0].Title = "PSA PANAMA"
+	now = now.Add(2 * time.Minute)
+	if term, _, _ := catalog.Resolve(ctx, "RODMAN"); term.Title != "PSA PANAMA" || repo.lists != 3 {
+		t.Fatalf("expected reload after ttl, got %q with %d loads", term.Title, repo.lists)
+	}
+}
+
+func TestTerminalCatalogValidatesInput(t *testing.T) {
+	catalog := NewTerminalCatalog(&memTerminalRepo{}, 0)
+	for _, in := range []TerminalInput{
+		{Code: "P", Title: "X"},
+		{Code: "PA-ROD", Title: "X"},
+		{Code: "PAROD", Title: " "},
+		{Code: "PAROD", Title: "X", LogoURL: "ftp://logos/rodman.png"},
+		{Code: "PAROD", Title: "X", Aliases: []string{"--"}},
+	} {
+		if _, err := catalog.Create(context.Background(), in); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("%+v: expected ErrInvalidInput, got %v", in, err)
+		}
+	}
+}
+
+func TestCreateUnknownPortPolicy(t *testing.T) {
+	repo := &memTerminalRepo{terminals: []domain.Terminal{{ID: 1, Code: "PAROD", Title: "PANAMA PORTS COMPANY (RODMAN)", Aliases: []string{"RODMAN"}}}}
+	in := domain.CreateRecordInput{
+		Nave: "NAVE TEST", Viaje: "VJ001", Cliente: "CLIENTE TEST", Booking: "BK001", ContenedorSerie: "ABCU1234560",
+		FechaReal: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), PuertoDescargue: "Rodman", UsuarioFirma: "user-1",
+	}
+	insert := mockRepo{insertFn: func(_ context.Context, _ domain.Record) (int64, error) { return 1, nil }}
+
+	svc := NewRecordService(insert).WithTerminals(NewTerminalCatalog(repo, time.Minute), false)
+	_, rec, err := svc.Create(context.Background(), in)
+	if err != nil || rec.TituloTerminal != "PANAMA PORTS COMPANY (RODMAN)" || rec.UnknownTerminal {
+		t.Fatalf("unexpected record: %+v (%v)", rec, err)
+	}
+
+	in.PuertoDescargue = "Vacamonte"
+	_, rec, err = svc.Create(context.Background(), in)
+	if err != nil || rec.TituloTerminal != "TERMINAL Vacamonte" || !rec.UnknownTerminal {
+		t.Fatalf("expected flagged record, got %+v (%v)", rec, err)
+	}
+
+	svc = NewRecordService(insert).WithTerminals(NewTerminalCatalog(repo, time.Minute), true)
+	if _, _, err := svc.Create(context.Background(), in); !errors.Is(err, domain.ErrUnknownTerminal) || !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected unknown terminal rejection, got %v", err)
+	}
+}
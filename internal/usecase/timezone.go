@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+	_ "time/tzdata" // terminal timezones must resolve in images without a zoneinfo database
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+var (
+	locations       sync.Map // IANA name -> *time.Location
+	defaultLocation = mustLoadLocation(domain.DefaultTimezone)
+)
+
+// loadLocation is time.LoadLocation with a process-wide cache, as the timezone of a terminal is
+// looked up for every pass validated or formatted.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := loadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// WithTimezone sets the operating timezone of passes whose terminal does not set one.
+func (s *RecordService) WithTimezone(loc *time.Location) *RecordService {
+	s.location = loc
+	return s
+}
+
+// DefaultLocation returns the operating timezone used when no terminal applies, e.g. for date
+// filters spanning every terminal.
+func (s *RecordService) DefaultLocation() *time.Location {
+	if s.location == nil {
+		return defaultLocation
+	}
+	return s.location
+}
+
+// Location returns the operating timezone of the terminal serving rec. Business dates of the
+// pass, such as today when checking expiry, are taken in it; instants are still stored in UTC.
+// Ports without a terminal, terminals without a timezone and lookup failures fall back to
+// DefaultLocation.
+func (s *RecordService) Location(ctx context.Context, rec domain.Record) *time.Location {
+	t, ok, err := s.LookupTerminal(ctx, rec)
+	if err != nil || !ok || t.Timezone == "" {
+		return s.DefaultLocation()
+	}
+	loc, err := loadLocation(t.Timezone)
+	if err != nil {
+		return s.DefaultLocation()
+	}
+	return loc
+}
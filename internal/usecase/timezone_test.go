@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/validacion-pases/internal/domain"
+)
+
+func TestValidateQRTokenUsesTerminalTimezone(t *testing.T) {
+	repo := &memTerminalRepo{terminals: []domain.Terminal{
+		{ID: 1, Code: "PABLB", Title: "TERMINAL PACIFICO - BALBOA", Aliases: []string{"BALBOA"}},
+		{ID: 2, Code: "MXZLO", Title: "TERMINAL MANZANILLO MX", Timezone: "America/Mexico_City"},
+		{ID: 3, Code: "ESALG", Title: "TERMINAL ALGECIRAS", Timezone: "Europe/Madrid"},
+	}}
+	records := map[int64]domain.Record{
+		1: {ID: 1, PuertoDescargue: "BALBOA"},
+		2: {ID: 2, PuertoDescargue: "MXZLO"},
+		3: {ID: 3, PuertoDescargue: "ESALG"},
+		4: {ID: 4, PuertoDescargue: "PUERTO DESCONOCIDO"},
+	}
+	svc := NewRecordService(
+		mockRepo{findByIDFn: func(_ context.Context, id int64) (domain.Record, error) {
+			rec := records[id]
+			rec.Status, rec.LibreRetencionHasta = domain.StatusIssued, time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+			return rec, nil
+		}},
+		mockVerifier{verifyFn: func(token string) (int64, error) { return int64(token[0] - '0'), nil }},
+	).WithTerminals(NewTerminalCatalog(repo, time.Minute), false)
+	// 20:30 on the last free day in Panama, 19:30 in Mexico City, already the next day in Madrid.
+	svc.nowFn = func() time.Time { return time.Date(2026, 3, 7, 1, 30, 0, 0, time.UTC) }
+
+	for token, want := range map[string]domain.RecordStatus{
+		"1": domain.StatusIssued,
+		"2": domain.StatusIssued,
+		"3": domain.StatusExpired,
+		"4": domain.StatusIssued, // unknown port: operating timezone
+	} {
+		result, err := svc.ValidateQRToken(context.Background(), token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Status != want {
+			t.Errorf("record %s: expected %s, got %s", token, want, result.Status)
+		}
+	}
+
+	svc.WithTimezone(time.UTC)
+	if result, _ := svc.ValidateQRToken(context.Background(), "4"); result.Status != domain.StatusExpired {
+		t.Fatalf("expected the configured operating timezone to apply, got %s", result.Status)
+	}
+}
+
+func TestTerminalCatalogRejectsUnknownTimezone(t *testing.T) {
+	catalog := NewTerminalCatalog(&memTerminalRepo{}, 0)
+	for _, tz := range []string{"America/Atlantis", "Local"} {
+		_, err := catalog.Create(context.Background(), TerminalInput{Code: "PAROD", Title: "RODMAN", Timezone: tz})
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("%s: expected ErrInvalidInput, got %v", tz, err)
+		}
+	}
+	created, err := catalog.Create(context.Background(), TerminalInput{Code: "PAROD", Title: "RODMAN", Timezone: " America/Panama "})
+	if err != nil || created.Timezone != "America/Panama" {
+		t.Fatalf("unexpected terminal %+v, %v", created, err)
+	}
+}
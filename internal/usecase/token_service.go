@@ -29,5 +29,5 @@ func (s *TokenService) Issue(username, password string) (string, time.Time, erro
 	if !s.users.Validate(username, password) {
 		return "", time.Time{}, ErrInvalidCredentials
 	}
-	return s.issuer.Issue(strings.TrimSpace(username))
+	return s.issuer.Issue(strings.TrimSpace(username), s.users.Scopes(username)...)
 }
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS record_revocations (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    record_id BIGINT NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    revoked_by VARCHAR(100) NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_record_revocations_record (record_id),
+    CONSTRAINT fk_record_revocations_record FOREIGN KEY (record_id) REFERENCES records (id)
+);
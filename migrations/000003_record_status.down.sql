@@ -0,0 +1,3 @@
+ALTER TABLE records
+    DROP COLUMN status_updated_at,
+    DROP COLUMN status;
@@ -0,0 +1,7 @@
+ALTER TABLE records
+    ADD COLUMN status ENUM('issued', 'used', 'expired', 'revoked', 'superseded') NOT NULL DEFAULT 'issued' AFTER usuario_firma,
+    ADD COLUMN status_updated_at TIMESTAMP NULL AFTER status;
+
+UPDATE records r
+JOIN record_revocations rr ON rr.record_id = r.id
+SET r.status = 'revoked', r.status_updated_at = rr.revoked_at;
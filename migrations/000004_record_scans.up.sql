@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS record_scans (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    record_id BIGINT NULL,
+    outcome ENUM('valid', 'expired', 'invalid_signature', 'not_found', 'revoked', 'used', 'superseded') NOT NULL,
+    scanner VARCHAR(100) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT '',
+    scanned_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY idx_record_scans_record (record_id, scanned_at),
+    KEY idx_record_scans_scanned_at (scanned_at)
+);
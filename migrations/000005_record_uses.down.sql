@@ -0,0 +1,3 @@
+ALTER TABLE records
+    DROP COLUMN use_count,
+    DROP COLUMN max_uses;
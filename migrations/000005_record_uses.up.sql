@@ -0,0 +1,3 @@
+ALTER TABLE records
+    ADD COLUMN max_uses INT NULL AFTER status_updated_at,
+    ADD COLUMN use_count INT NOT NULL DEFAULT 0 AFTER max_uses;
@@ -0,0 +1,8 @@
+DROP INDEX idx_records_created_at ON records;
+DROP INDEX idx_records_emision ON records;
+DROP INDEX idx_records_usuario_firma ON records;
+DROP INDEX idx_records_puerto_descargue ON records;
+DROP INDEX idx_records_contenedor ON records;
+DROP INDEX idx_records_cliente ON records;
+DROP INDEX idx_records_nave ON records;
+DROP INDEX idx_records_viaje ON records;
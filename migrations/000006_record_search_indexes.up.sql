@@ -0,0 +1,8 @@
+CREATE INDEX idx_records_viaje ON records (viaje);
+CREATE INDEX idx_records_nave ON records (nave);
+CREATE INDEX idx_records_cliente ON records (cliente);
+CREATE INDEX idx_records_contenedor ON records (contenedor);
+CREATE INDEX idx_records_puerto_descargue ON records (puerto_descargue);
+CREATE INDEX idx_records_usuario_firma ON records (usuario_firma);
+CREATE INDEX idx_records_emision ON records (emision);
+CREATE INDEX idx_records_created_at ON records (created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS record_versions;
+
+ALTER TABLE records
+    DROP COLUMN version;
@@ -0,0 +1,13 @@
+ALTER TABLE records
+    ADD COLUMN version INT NOT NULL DEFAULT 1 AFTER use_count;
+
+CREATE TABLE IF NOT EXISTS record_versions (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    record_id BIGINT NOT NULL,
+    version INT NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changes JSON NOT NULL,
+    UNIQUE KEY uq_record_versions_record_version (record_id, version),
+    CONSTRAINT fk_record_versions_record FOREIGN KEY (record_id) REFERENCES records (id)
+);
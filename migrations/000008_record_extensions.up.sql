@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS record_extensions (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    record_id BIGINT NOT NULL,
+    days INT NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    approved_by VARCHAR(100) NOT NULL,
+    requested_by VARCHAR(100) NOT NULL,
+    previous_until DATE NOT NULL,
+    new_until DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_record_extensions_record (record_id, id),
+    CONSTRAINT fk_record_extensions_record FOREIGN KEY (record_id) REFERENCES records (id)
+);
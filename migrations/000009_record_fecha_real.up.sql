@@ -0,0 +1,8 @@
+ALTER TABLE records
+    ADD COLUMN fecha_real DATE NULL AFTER puerto_descargue;
+
+UPDATE records
+SET fecha_real = DATE_SUB(libre_retencion_hasta, INTERVAL dias_libre DAY);
+
+ALTER TABLE records
+    MODIFY COLUMN fecha_real DATE NOT NULL;
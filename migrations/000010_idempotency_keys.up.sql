@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    subject VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    response_body MEDIUMBLOB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    UNIQUE KEY uq_idempotency_keys_subject_key (subject, idempotency_key),
+    KEY idx_idempotency_keys_expires_at (expires_at)
+);
@@ -0,0 +1,2 @@
+DROP INDEX idx_records_titulo_terminal_emision ON records;
+DROP INDEX idx_records_cliente_emision ON records;
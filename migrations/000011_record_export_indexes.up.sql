@@ -0,0 +1,2 @@
+CREATE INDEX idx_records_cliente_emision ON records (cliente, emision);
+CREATE INDEX idx_records_titulo_terminal_emision ON records (titulo_terminal, emision);
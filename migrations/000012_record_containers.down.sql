@@ -0,0 +1,6 @@
+ALTER TABLE records
+    ADD UNIQUE KEY uq_booking_viaje_contenedor (booking, viaje, contenedor);
+
+DROP INDEX idx_records_booking_viaje ON records;
+
+DROP TABLE IF EXISTS record_containers;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS record_containers (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    record_id BIGINT NOT NULL,
+    line_no INT NOT NULL,
+    booking VARCHAR(100) NOT NULL,
+    viaje VARCHAR(100) NOT NULL,
+    contenedor_serie VARCHAR(120) NOT NULL DEFAULT '',
+    codigo_iso VARCHAR(20) NOT NULL DEFAULT '',
+    cantidad INT NOT NULL DEFAULT 1,
+    UNIQUE KEY uq_record_containers_line (record_id, line_no),
+    UNIQUE KEY uq_record_containers_booking_viaje_contenedor (booking, viaje, contenedor_serie, codigo_iso),
+    CONSTRAINT fk_record_containers_record FOREIGN KEY (record_id) REFERENCES records (id) ON DELETE CASCADE
+);
+
+INSERT INTO record_containers (record_id, line_no, booking, viaje, contenedor_serie, codigo_iso, cantidad)
+SELECT id, 1, booking, viaje,
+       IF(rama = 'internacional', contenedor, ''),
+       IF(rama = 'nacional', IF(contenedor LIKE '1 X %', SUBSTRING(contenedor, 5), contenedor), ''),
+       1
+FROM records;
+
+CREATE INDEX idx_records_booking_viaje ON records (booking, viaje);
+
+ALTER TABLE records
+    DROP INDEX uq_booking_viaje_contenedor;
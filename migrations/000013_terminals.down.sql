@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS terminal_aliases;
+DROP TABLE IF EXISTS terminals;
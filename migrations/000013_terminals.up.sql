@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS terminals (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    code VARCHAR(20) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    address VARCHAR(300) NOT NULL DEFAULT '',
+    logo_url VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_terminals_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS terminal_aliases (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    terminal_id BIGINT NOT NULL,
+    alias VARCHAR(150) NOT NULL,
+    UNIQUE KEY uq_terminal_aliases_alias (alias),
+    CONSTRAINT fk_terminal_aliases_terminal FOREIGN KEY (terminal_id) REFERENCES terminals (id) ON DELETE CASCADE
+);
+
+INSERT INTO terminals (code, title) VALUES
+    ('PABLB', 'TERMINAL PACIFICO - BALBOA'),
+    ('PACTB', 'TERMINAL ATLANTICO - CRISTOBAL'),
+    ('PAMIT', 'MANZANILLO INTERNATIONAL TERMINAL');
+
+INSERT INTO terminal_aliases (terminal_id, alias)
+SELECT id, CASE code WHEN 'PABLB' THEN 'BALBOA' WHEN 'PACTB' THEN 'CRISTOBAL' ELSE 'MANZANILLO' END
+FROM terminals;
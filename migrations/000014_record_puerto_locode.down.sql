@@ -0,0 +1,4 @@
+ALTER TABLE records
+    DROP KEY idx_records_puerto_locode,
+    DROP COLUMN puerto_nombre,
+    DROP COLUMN puerto_locode;
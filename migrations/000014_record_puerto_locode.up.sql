@@ -0,0 +1,15 @@
+ALTER TABLE records
+    ADD COLUMN puerto_locode VARCHAR(5) NOT NULL DEFAULT '' AFTER puerto_descargue,
+    ADD COLUMN puerto_nombre VARCHAR(150) NOT NULL DEFAULT '' AFTER puerto_locode,
+    ADD KEY idx_records_puerto_locode (puerto_locode);
+
+-- Existing records are normalized for the ports the service used to recognize; any other port
+-- keeps an empty code until the record is amended.
+UPDATE records SET puerto_locode = 'PABLB', puerto_nombre = 'Balboa, Panamá'
+WHERE UPPER(puerto_descargue) LIKE '%BALBOA%';
+
+UPDATE records SET puerto_locode = 'PACTB', puerto_nombre = 'Cristóbal, Panamá'
+WHERE puerto_locode = '' AND UPPER(puerto_descargue) LIKE '%CRISTOBAL%';
+
+UPDATE records SET puerto_locode = 'PAMIT', puerto_nombre = 'Manzanillo, Panamá'
+WHERE puerto_locode = '' AND UPPER(puerto_descargue) LIKE '%MANZANILLO%';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS terminal_holidays;
+DROP TABLE IF EXISTS client_free_time_policies;
+
+ALTER TABLE terminals
+    DROP COLUMN free_time_policy;
+
+ALTER TABLE records
+    DROP COLUMN free_time_policy;
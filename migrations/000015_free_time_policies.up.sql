@@ -0,0 +1,39 @@
+ALTER TABLE records
+    ADD COLUMN free_time_policy VARCHAR(20) NOT NULL DEFAULT 'calendar_days' AFTER dias_libre;
+
+ALTER TABLE terminals
+    ADD COLUMN free_time_policy VARCHAR(20) NOT NULL DEFAULT 'calendar_days' AFTER logo_url;
+
+-- Clients whose contracts count free days differently from the terminal.
+CREATE TABLE IF NOT EXISTS client_free_time_policies (
+    cliente VARCHAR(200) NOT NULL PRIMARY KEY,
+    free_time_policy VARCHAR(20) NOT NULL
+);
+
+-- An empty terminal_code applies to every terminal. Yearly holidays repeat on the same day from
+-- fecha onwards.
+CREATE TABLE IF NOT EXISTS terminal_holidays (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    terminal_code VARCHAR(20) NOT NULL DEFAULT '',
+    fecha DATE NOT NULL,
+    nombre VARCHAR(150) NOT NULL,
+    yearly BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE KEY uq_terminal_holidays_day (terminal_code, fecha)
+);
+
+-- Panamanian public holidays. Carnival and Good Friday move every year and are loaded per year.
+INSERT INTO terminal_holidays (fecha, nombre, yearly) VALUES
+    ('2026-01-01', 'Año Nuevo', TRUE),
+    ('2026-01-09', 'Día de los Mártires', TRUE),
+    ('2026-05-01', 'Día del Trabajo', TRUE),
+    ('2026-11-03', 'Separación de Panamá de Colombia', TRUE),
+    ('2026-11-04', 'Día de los Símbolos Patrios', TRUE),
+    ('2026-11-05', 'Día de la Consolidación de la Separación', TRUE),
+    ('2026-11-10', 'Primer Grito de Independencia', TRUE),
+    ('2026-11-28', 'Independencia de Panamá de España', TRUE),
+    ('2026-12-08', 'Día de las Madres', TRUE),
+    ('2026-12-20', 'Día de Duelo Nacional', TRUE),
+    ('2026-12-25', 'Navidad', TRUE),
+    ('2026-02-16', 'Carnaval', FALSE),
+    ('2026-02-17', 'Carnaval', FALSE),
+    ('2026-04-03', 'Viernes Santo', FALSE);
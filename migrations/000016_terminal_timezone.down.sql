@@ -0,0 +1,2 @@
+ALTER TABLE terminals
+    DROP COLUMN timezone;
@@ -0,0 +1,3 @@
+-- IANA zone the terminal operates in; empty uses OPERATING_TIMEZONE (America/Panama by default).
+ALTER TABLE terminals
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '' AFTER free_time_policy;
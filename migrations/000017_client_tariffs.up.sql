@@ -0,0 +1,16 @@
+-- Free time contracted per client. Empty rama and container_type (ISO size/type code) match any
+-- pass; valid_to is inclusive and NULL for open-ended tariffs.
+CREATE TABLE IF NOT EXISTS client_tariffs (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    cliente VARCHAR(200) NOT NULL,
+    rama VARCHAR(20) NOT NULL DEFAULT '',
+    container_type VARCHAR(4) NOT NULL DEFAULT '',
+    default_dias_libre INT NOT NULL,
+    max_dias_libre INT NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_client_tariffs_cliente (cliente),
+    CONSTRAINT chk_client_tariffs_dias CHECK (default_dias_libre >= 0 AND default_dias_libre <= max_dias_libre),
+    CONSTRAINT chk_client_tariffs_validity CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
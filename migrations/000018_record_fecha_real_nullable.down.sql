@@ -0,0 +1,6 @@
+UPDATE records
+SET fecha_real = DATE_SUB(libre_retencion_hasta, INTERVAL dias_libre DAY)
+WHERE fecha_real IS NULL;
+
+ALTER TABLE records
+    MODIFY COLUMN fecha_real DATE NOT NULL;
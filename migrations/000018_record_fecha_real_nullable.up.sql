@@ -0,0 +1,4 @@
+-- NULL marks passes created from a legacy payload with only libre_retencion_hasta; their
+-- fecha_real is derived as libre_retencion_hasta - dias_libre when read.
+ALTER TABLE records
+    MODIFY COLUMN fecha_real DATE NULL;
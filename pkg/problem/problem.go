@@ -6,11 +6,28 @@ import (
 )
 
 type Details struct {
-	Type     string `json:"type"`
-	Title    string `json:"title"`
-	Status   int    `json:"status"`
-	Detail   string `json:"detail"`
-	Instance string `json:"instance,omitempty"`
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam points at one rejected request parameter (RFC 7807 extension member). Part names
+// the component of the value that is wrong and Expected carries the corrected component when the
+// server can compute it.
+type InvalidParam struct {
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+	Part     string `json:"part,omitempty"`
+	Expected string `json:"expected,omitempty"`
+}
+
+// WithInvalidParams returns a copy of p listing params.
+func (p Details) WithInvalidParams(params ...InvalidParam) Details {
+	p.InvalidParams = append(append([]InvalidParam(nil), p.InvalidParams...), params...)
+	return p
 }
 
 func Write(w http.ResponseWriter, r *http.Request, p Details) {
@@ -35,6 +52,15 @@ func Conflict(detail string) Details {
 func NotFound(detail string) Details {
 	return Details{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: detail}
 }
+func PreconditionFailed(detail string) Details {
+	return Details{Type: "about:blank", Title: "Precondition Failed", Status: http.StatusPreconditionFailed, Detail: detail}
+}
+func PreconditionRequired(detail string) Details {
+	return Details{Type: "about:blank", Title: "Precondition Required", Status: http.StatusPreconditionRequired, Detail: detail}
+}
+func UnprocessableEntity(detail string) Details {
+	return Details{Type: "about:blank", Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity, Detail: detail}
+}
 func Internal(detail string) Details {
 	return Details{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError, Detail: detail}
 }
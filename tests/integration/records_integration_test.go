@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -19,12 +20,18 @@ import (
 func TestInsertRecordIntegration(t *testing.T) {
 	ctx := context.Background()
 
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(migrations)
+
 	container, err := mysqltc.Run(ctx,
 		"mysql:8.4",
 		mysqltc.WithDatabase("validacion_pases"),
 		mysqltc.WithUsername("app"),
 		mysqltc.WithPassword("app"),
-		mysqltc.WithScripts(filepath.Join("..", "..", "migrations", "000001_init.up.sql")),
+		mysqltc.WithScripts(migrations...),
 		testcontainers.WithWaitStrategy(wait.ForLog("port: 3306  MySQL Community Server").WithStartupTimeout(2*time.Minute)),
 	)
 	if err != nil {
@@ -54,7 +61,7 @@ func TestInsertRecordIntegration(t *testing.T) {
 		Cliente:             "CLIENTE 1",
 		Booking:             "BK1",
 		Rama:                "internacional",
-		Contenedor:          "ABCU1234567",
+		Contenedor:          "ABCU1234560",
 		PuertoDescargue:     "Balboa",
 		LibreRetencionHasta: now.AddDate(0, 0, 2),
 		DiasLibre:           2,